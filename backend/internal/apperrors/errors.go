@@ -0,0 +1,77 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel kinds every domain error is classified as. Match them with
+// errors.Is; the HTTP layer maps each kind to a status code.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrUnavailable = errors.New("service unavailable")
+
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error records which operation failed, the kind of failure and the
+// underlying cause. A nil Kind marks an unclassified, internal failure.
+type Error struct {
+	Kind error
+	Op   string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("failed to %s: %v", e.Op, e.Kind)
+	}
+	return fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+}
+
+// Message describes err as clients may see it: the cause alone, without the
+// operations it failed in, which name internals. Errors without a cause are
+// described by their kind.
+func Message(err error) string {
+	var appErr *Error
+	for errors.As(err, &appErr) {
+		if appErr.Err == nil {
+			return appErr.Kind.Error()
+		}
+		err = appErr.Err
+	}
+	return err.Error()
+}
+
+// Unwrap exposes both the kind and the cause so errors.Is matches either.
+func (e *Error) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Kind, e.Err} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func NotFound(op string, err error) error {
+	return &Error{Kind: ErrNotFound, Op: op, Err: err}
+}
+
+func Conflict(op string, err error) error {
+	return &Error{Kind: ErrConflict, Op: op, Err: err}
+}
+
+func Validation(op string, err error) error {
+	return &Error{Kind: ErrValidation, Op: op, Err: err}
+}
+
+func Unavailable(op string, err error) error {
+	return &Error{Kind: ErrUnavailable, Op: op, Err: err}
+}
+
+func PreconditionFailed(op string, err error) error {
+	return &Error{Kind: ErrPreconditionFailed, Op: op, Err: err}
+}
@@ -0,0 +1,30 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("record not found")
+	err := NotFound("get order by ID", cause)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "failed to get order by ID: record not found", err.Error())
+
+	wrapped := Conflict("create customer", nil)
+	assert.ErrorIs(t, wrapped, ErrConflict)
+	assert.Equal(t, "failed to create customer: conflict", wrapped.Error())
+
+	assert.Equal(t, "record not found", Message(err))
+	assert.Equal(t, "record not found", Message(fmt.Errorf("update order: %w", NotFound("get order by ID", err))))
+	assert.Equal(t, "conflict", Message(wrapped))
+
+	var appErr *Error
+	assert.True(t, errors.As(Unavailable("get all orders", cause), &appErr))
+	assert.Equal(t, ErrUnavailable, appErr.Kind)
+}
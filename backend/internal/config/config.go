@@ -1,25 +1,70 @@
 package config
 
 import (
+	"crypto/rand"
 	"github.com/joho/godotenv"
 	"log"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Port               string
-	DBHost             string
-	DBPort             int
-	DBUser             string
-	DBPassword         string
-	DBName             string
-	SMSSandboxAPIKey   string
-	SMSSandboxUserName string
-	GithubClientID     string
-	GithubClientSecret string
-	CallbackUrl        string
-	Secret             string
+	LogLevel             string
+	LogFormat            string
+	Host                 string
+	Port                 string
+	ReadTimeout          time.Duration
+	WriteTimeout         time.Duration
+	IdleTimeout          time.Duration
+	BatchMaxItems        int
+	ShutdownTimeout      time.Duration
+	TLSCertFile          string
+	TLSKeyFile           string
+	HSTSMaxAge           time.Duration
+	SecureCookies        bool
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowCredentials bool
+	TrustedProxies       []string
+	DBDriver             string
+	DatabaseURL          string
+	DBHost               string
+	DBPort               int
+	DBUser               string
+	DBPassword           string
+	DBName               string
+	DBSSLMode            string
+	DBTimeZone           string
+	DBReadTimeout        time.Duration
+	DBWriteTimeout       time.Duration
+	IdempotencyTTL       time.Duration
+	IdempotencyLease     time.Duration
+	RateLimitDefault     string
+	RateLimitRoutes      string
+	RateLimitIP          string
+	OrderStreamPoll      time.Duration
+	OrderStreamHeartbeat time.Duration
+	OrderStreamGrace     time.Duration
+	TrashRetention       time.Duration
+	CleanupInterval      time.Duration
+	AdminUsers           []string
+	TokenCacheTTL        time.Duration
+	HealthTimeout        time.Duration
+	TracingExporter      string
+	TracingSampleRatio   float64
+	HealthCacheTTL       time.Duration
+	HealthCheckSMS       bool
+	HealthCheckAuth      bool
+	SMSSandboxAPIKey     string
+	SMSSandboxUserName   string
+	GithubClientID       string
+	GithubClientSecret   string
+	CallbackUrl          string
+	Secret               string
+	CursorSecret         []byte
 }
 
 var AppConfig Config
@@ -35,25 +80,190 @@ func Load() error {
 		log.Fatalf("Invalid port number for DB_PORT: %v", err)
 	}
 
+	databaseURL := getEnv("DATABASE_URL", "")
+	tlsCertFile := getEnv("TLS_CERT_FILE", "")
+	tlsKeyFile := getEnv("TLS_KEY_FILE", "")
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		log.Fatalf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	corsMethods := getList("CORS_ALLOWED_METHODS")
+	if len(corsMethods) == 0 {
+		corsMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	}
+	corsOrigins := getList("CORS_ALLOWED_ORIGINS")
+	corsCredentials := getBool("CORS_ALLOW_CREDENTIALS", false)
+	for _, origin := range corsOrigins {
+		if origin == "*" && corsCredentials {
+			log.Fatalf("CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS")
+		}
+	}
+
+	streamPoll := getDuration("ORDER_STREAM_POLL_INTERVAL", time.Second)
+	streamHeartbeat := getDuration("ORDER_STREAM_HEARTBEAT", 15*time.Second)
+	streamGrace := getDuration("ORDER_STREAM_GRACE", 2*time.Minute)
+	if streamPoll <= 0 || streamHeartbeat <= 0 || streamGrace <= 0 {
+		log.Fatalf("ORDER_STREAM_POLL_INTERVAL, ORDER_STREAM_HEARTBEAT and ORDER_STREAM_GRACE must be positive")
+	}
+
+	writeTimeout := getDuration("HTTP_WRITE_TIMEOUT", 30*time.Second)
+
 	AppConfig = Config{
-		Port:               getEnv("PORT", "8080"),
-		DBHost:             getEnv("DB_HOST", "localhost"),
-		DBPort:             dbPort,
-		DBUser:             getEnv("DB_USER", "user"),
-		DBPassword:         getEnv("DB_PASSWORD", "password"),
-		DBName:             getEnv("DB_NAME", "database"),
-		SMSSandboxAPIKey:   getEnv("SMS_SANDBOX_API_KEY", ""),
-		SMSSandboxUserName: getEnv("SMS_SANDBOX_API_USERNAME", ""),
-		GithubClientID:     getEnv("CLIENT_ID", ""),
-		GithubClientSecret: getEnv("CLIENT_SECRET", ""),
-		CallbackUrl:        getEnv("CALL_BACK_URL", ""),
-		Secret:             getEnv("SECRET", ""),
-	}
-
-	log.Printf("Configuration loaded successfully %+v", AppConfig)
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+		LogFormat:            getEnv("LOG_FORMAT", "json"),
+		Host:                 getEnv("HOST", ""),
+		Port:                 getEnv("PORT", "8080"),
+		ReadTimeout:          getDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:         writeTimeout,
+		IdleTimeout:          getDuration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		BatchMaxItems:        getInt("BATCH_MAX_ITEMS", 500),
+		ShutdownTimeout:      getDuration("SHUTDOWN_TIMEOUT", 20*time.Second),
+		TLSCertFile:          tlsCertFile,
+		TLSKeyFile:           tlsKeyFile,
+		HSTSMaxAge:           getDuration("HSTS_MAX_AGE", 365*24*time.Hour),
+		SecureCookies:        getBool("COOKIE_SECURE", tlsCertFile != ""),
+		CORSAllowedOrigins:   corsOrigins,
+		CORSAllowedMethods:   corsMethods,
+		CORSAllowCredentials: corsCredentials,
+		TrustedProxies:       getList("TRUSTED_PROXIES"),
+		DBDriver:             getEnv("DB_DRIVER", driverFromURL(databaseURL)),
+		DatabaseURL:          databaseURL,
+		DBHost:               getEnv("DB_HOST", "localhost"),
+		DBPort:               dbPort,
+		DBUser:               getEnv("DB_USER", "user"),
+		DBPassword:           getEnv("DB_PASSWORD", "password"),
+		DBName:               getEnv("DB_NAME", "database"),
+		DBSSLMode:            getEnv("DB_SSLMODE", "disable"),
+		DBTimeZone:           getEnv("DB_TIMEZONE", "Africa/Nairobi"),
+		DBReadTimeout:        getDuration("DB_READ_TIMEOUT", 5*time.Second),
+		DBWriteTimeout:       getDuration("DB_WRITE_TIMEOUT", 10*time.Second),
+		IdempotencyTTL:       getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyLease:     getDuration("IDEMPOTENCY_LEASE", writeTimeout+30*time.Second),
+		RateLimitDefault:     getEnv("RATE_LIMIT_DEFAULT", "600/m"),
+		RateLimitRoutes:      getEnv("RATE_LIMIT_ROUTES", "POST /api/v1/orders=30/m"),
+		RateLimitIP:          getEnv("RATE_LIMIT_IP", "1200/m"),
+		OrderStreamPoll:      streamPoll,
+		OrderStreamHeartbeat: streamHeartbeat,
+		OrderStreamGrace:     streamGrace,
+		TrashRetention:       getDuration("TRASH_RETENTION", 30*24*time.Hour),
+		CleanupInterval:      getDuration("CLEANUP_INTERVAL", time.Hour),
+		AdminUsers:           getList("ADMIN_USERS"),
+		TokenCacheTTL:        getDuration("TOKEN_CACHE_TTL", time.Minute),
+		HealthTimeout:        getDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+		TracingExporter:      getEnv("TRACING_EXPORTER", "none"),
+		TracingSampleRatio:   getFloat("TRACING_SAMPLE_RATIO", 1),
+		HealthCacheTTL:       getDuration("HEALTH_CACHE_TTL", 30*time.Second),
+		HealthCheckSMS:       getBool("HEALTH_CHECK_SMS", false),
+		HealthCheckAuth:      getBool("HEALTH_CHECK_AUTH", false),
+		SMSSandboxAPIKey:     getEnv("SMS_SANDBOX_API_KEY", ""),
+		SMSSandboxUserName:   getEnv("SMS_SANDBOX_API_USERNAME", ""),
+		GithubClientID:       getEnv("CLIENT_ID", ""),
+		GithubClientSecret:   getEnv("CLIENT_SECRET", ""),
+		CallbackUrl:          getEnv("CALL_BACK_URL", ""),
+		Secret:               getEnv("SECRET", ""),
+		CursorSecret:         cursorSecret(),
+	}
+
+	log.Printf("Configuration loaded successfully (driver=%s, port=%s)", AppConfig.DBDriver, AppConfig.Port)
 	return nil
 }
 
+// cursorSecret is the key pagination cursors are signed with. Without
+// CURSOR_SECRET a random key is used, so cursors are only valid on this
+// instance until it restarts.
+func cursorSecret() []byte {
+	if secret := getEnv("CURSOR_SECRET", ""); secret != "" {
+		return []byte(secret)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate a cursor secret: %v", err)
+	}
+	log.Println("CURSOR_SECRET is not set, signing pagination cursors with a random key")
+	return secret
+}
+
+// TLS reports whether the server serves HTTPS itself.
+func (c Config) TLS() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// Addr is the host:port the HTTP server listens on. An empty HOST listens on
+// every interface.
+func (c Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// driverFromURL infers the database driver from the scheme of DATABASE_URL,
+// falling back to postgres when no URL is configured.
+func driverFromURL(databaseURL string) string {
+	switch {
+	case strings.HasPrefix(databaseURL, "sqlite:"), strings.HasPrefix(databaseURL, "file:"):
+		return "sqlite"
+	default:
+		return "postgres"
+	}
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+	return duration
+}
+
+func getInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid number for %s: %v", key, err)
+	}
+	return i
+}
+
+func getFloat(key string, defaultValue float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("Invalid number for %s: %v", key, err)
+	}
+	return f
+}
+
+func getBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid boolean for %s: %v", key, err)
+	}
+	return b
+}
+
+// getList splits a comma separated variable, dropping blank entries.
+func getList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
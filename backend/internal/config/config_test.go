@@ -9,3 +9,9 @@ func TestLoad(t *testing.T) {
 	err := Load()
 	assert.NoError(t, err, "Error loading config")
 }
+
+func TestAddr(t *testing.T) {
+	assert.Equal(t, ":8080", Config{Port: "8080"}.Addr())
+	assert.Equal(t, "127.0.0.1:9000", Config{Host: "127.0.0.1", Port: "9000"}.Addr())
+	assert.Equal(t, "[::1]:9000", Config{Host: "::1", Port: "9000"}.Addr())
+}
@@ -1,7 +1,36 @@
 package dto
 
+// BaseResponse wraps the data of successful responses. Errors are reported as
+// a Problem instead.
 type BaseResponse struct {
 	Data       interface{} `json:"data"`
 	Message    string      `json:"message"`
 	StatusCode int         `json:"status_code"`
+	Meta       interface{} `json:"meta,omitempty"`
+}
+
+// Pagination describes where a page of list results sits in the full result set.
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPagination builds the pagination metadata for a page of size limit out of total results.
+func NewPagination(page, limit int, total int64) *Pagination {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+	return &Pagination{Page: page, Limit: limit, Total: total, TotalPages: totalPages}
+}
+
+// CursorPagination links a keyset page to its neighbours. Pass a cursor back
+// as the cursor query parameter to fetch that page; an empty cursor means
+// there is no page in that direction.
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
@@ -0,0 +1,40 @@
+package dto
+
+// Batch modes. An atomic batch is written in one transaction and rolled back
+// as a whole when any item fails; a best-effort batch keeps the items that
+// succeed.
+const (
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+)
+
+// BatchCreateOrdersRequest is the body of order batch requests.
+type BatchCreateOrdersRequest struct {
+	Mode  string               `json:"mode,omitempty" binding:"omitempty,oneof=atomic best_effort" example:"atomic"`
+	Items []CreateOrderRequest `json:"items" binding:"required,min=1"`
+}
+
+// BatchCreateCustomersRequest is the body of customer batch requests.
+type BatchCreateCustomersRequest struct {
+	Mode  string            `json:"mode,omitempty" binding:"omitempty,oneof=atomic best_effort" example:"best_effort"`
+	Items []CustomerRequest `json:"items" binding:"required,min=1"`
+}
+
+// BatchResult reports the outcome of every item of a batch, in request order.
+type BatchResult struct {
+	Mode      string            `json:"mode" example:"atomic"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+// BatchItemResult is the outcome of one batch item. Status is the code the
+// item would have got as a single request; items of a failed atomic batch
+// that were not at fault are 424 Failed Dependency.
+type BatchItemResult struct {
+	Index  int         `json:"index"`
+	Status int         `json:"status" example:"201"`
+	ID     uint        `json:"id,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  *Problem    `json:"error,omitempty"`
+}
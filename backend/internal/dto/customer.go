@@ -0,0 +1,16 @@
+package dto
+
+// CustomerRequest is the body of customer create and update requests.
+type CustomerRequest struct {
+	Name  string `json:"name" binding:"required,max=255"`
+	Code  string `json:"code" binding:"required,customer_code"`
+	Phone string `json:"phone,omitempty" binding:"omitempty,kenyan_phone"`
+}
+
+// CustomerPatch lists the customer fields PATCH may change. Only fields
+// present in the patch are validated.
+type CustomerPatch struct {
+	Name  string `json:"name" binding:"required,max=255"`
+	Code  string `json:"code" binding:"required,customer_code"`
+	Phone string `json:"phone" binding:"omitempty,kenyan_phone"`
+}
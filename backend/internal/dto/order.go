@@ -1,7 +1,30 @@
 package dto
 
+// CreateOrderRequest is the body of order create requests.
 type CreateOrderRequest struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
-	UserId    int `json:"user_id"`
+	ProductID int     `json:"product_id" binding:"required,gt=0"`
+	Quantity  int     `json:"quantity" binding:"required,gt=0"`
+	UserId    int     `json:"user_id" binding:"required,gt=0"`
+	Total     float64 `json:"total,omitempty" binding:"omitempty,positive_money"`
+	Status    string  `json:"status,omitempty" binding:"omitempty,oneof=pending confirmed delivered cancelled"`
+}
+
+// ReplaceOrderRequest is the body of PUT requests, which replace the whole
+// order, so every field is required.
+type ReplaceOrderRequest struct {
+	ProductID int     `json:"product_id" binding:"required,gt=0"`
+	Quantity  int     `json:"quantity" binding:"required,gt=0"`
+	UserId    int     `json:"user_id" binding:"required,gt=0"`
+	Total     float64 `json:"total" binding:"positive_money"`
+	Status    string  `json:"status" binding:"required,oneof=pending confirmed delivered cancelled"`
+}
+
+// OrderPatch lists the order fields PATCH may change. Only fields present in
+// the patch are validated.
+type OrderPatch struct {
+	ProductID int     `json:"product_id" binding:"gt=0"`
+	Quantity  int     `json:"quantity" binding:"gt=0"`
+	UserId    int     `json:"user_id" binding:"gt=0"`
+	Total     float64 `json:"total" binding:"positive_money"`
+	Status    string  `json:"status" binding:"oneof=pending confirmed delivered cancelled"`
 }
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"backend/internal/models"
+	"time"
+)
+
+// Order event types, sent as the event field of the order stream.
+const (
+	OrderCreated       = "order.created"
+	OrderUpdated       = "order.updated"
+	OrderStatusChanged = "order.status_changed"
+	OrderDeleted       = "order.deleted"
+	OrderRestored      = "order.restored"
+	OrderPurged        = "order.purged"
+)
+
+// OrderEvent is the data of one order stream event. Its ID is the ID of the
+// audit entry it was read from, by which clients skip repeated events. It is
+// not the SSE event ID: that is the stream's settled resume position, which
+// may lag behind it, and only that is valid as Last-Event-ID.
+type OrderEvent struct {
+	ID        uint                          `json:"id"`
+	Type      string                        `json:"type" example:"order.status_changed"`
+	OrderID   uint                          `json:"order_id"`
+	Actor     string                        `json:"actor"`
+	RequestID string                        `json:"request_id,omitempty"`
+	Changes   map[string]models.FieldChange `json:"changes"`
+	CreatedAt time.Time                     `json:"created_at"`
+}
+
+// NewOrderEvent describes the change recorded in an audit entry of an order.
+// Updates that change the status are status_changed events.
+func NewOrderEvent(entry models.AuditLog) OrderEvent {
+	event := OrderEvent{
+		ID:        entry.ID,
+		OrderID:   entry.EntityID,
+		Actor:     entry.Actor,
+		RequestID: entry.RequestID,
+		Changes:   entry.Changes,
+		CreatedAt: entry.CreatedAt,
+	}
+	switch entry.Action {
+	case models.AuditCreate:
+		event.Type = OrderCreated
+	case models.AuditUpdate:
+		event.Type = OrderUpdated
+		if _, ok := entry.Changes["status"]; ok {
+			event.Type = OrderStatusChanged
+		}
+	case models.AuditDelete:
+		event.Type = OrderDeleted
+	case models.AuditRestore:
+		event.Type = OrderRestored
+	case models.AuditPurge:
+		event.Type = OrderPurged
+	}
+	return event
+}
@@ -0,0 +1,21 @@
+package dto
+
+// Problem is an RFC 7807 problem details document, the body of every error
+// response. request_id and errors are extension members.
+type Problem struct {
+	Type      string       `json:"type" example:"https://github.com/paulodhiambo/savannah/blob/main/docs/problems.md#not-found"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"failed to get order by ID: record not found"`
+	Instance  string       `json:"instance,omitempty" example:"/api/v1/orders/42"`
+	RequestID string       `json:"request_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError explains why one request field was rejected. Code names the
+// failed rule, e.g. required, max or kenyan_phone.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"backend/internal/dto"
+	"backend/internal/models"
+	"backend/internal/problem"
+	"backend/internal/query"
+	"backend/internal/repositories"
+	"backend/pkg/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+// auditListSpec whitelists the audit log columns list endpoints may sort and filter on.
+var auditListSpec = query.Spec{
+	Sortable:    []string{"id", "created_at"},
+	DefaultSort: "-id",
+	Filters: map[string]query.Filter{
+		"entity":         {Column: "entity", Parse: query.OneOf("order", "customer", "product")},
+		"id":             {Column: "entity_id", Parse: query.Int},
+		"action":         {Column: "action", Parse: query.OneOf(models.AuditActions...), Multiple: true},
+		"actor":          {Column: "actor", Parse: query.String},
+		"request_id":     {Column: "request_id", Parse: query.String},
+		"created_after":  {Column: "created_at", Operator: query.AtLeast, Parse: query.Time},
+		"created_before": {Column: "created_at", Operator: query.AtMost, Parse: query.Time},
+	},
+}
+
+type AuditHandler struct {
+	repo   repositories.AuditRepositoryImpl
+	logger *logrus.Logger
+}
+
+func NewAuditHandler(repo repositories.AuditRepositoryImpl, logger *logrus.Logger) *AuditHandler {
+	return &AuditHandler{repo: repo, logger: logger}
+}
+
+// GetAuditLog @Summary Search the audit log
+// @Description Get a page of audit entries, newest first. Administrators only.
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param entity query string false "Entity type: order, customer or product"
+// @Param id query int false "Entity ID"
+// @Param action query string false "Filter by action (create, update, delete, restore, purge); comma separated for several"
+// @Param actor query string false "Filter by actor login"
+// @Param request_id query string false "Filter by request ID"
+// @Param created_after query string false "Only entries recorded at or after this RFC 3339 time or date"
+// @Param created_before query string false "Only entries recorded at or before this RFC 3339 time or date"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (default -id)"
+// @Success 200 {object} dto.BaseResponse{data=[]models.AuditLog}
+// @Security ApiKeyAuth
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	q, page, err := query.Parse(c.Request.URL.Query(), auditListSpec)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("invalid audit query: %v", err)
+		_ = c.Error(err)
+		return
+	}
+	h.list(c, q, page)
+}
+
+// GetOrderHistory @Summary Get the history of an order
+// @Description Get a page of the audit entries of one order, newest first. Administrators see any order; other users only their own.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Success 200 {object} dto.BaseResponse{data=[]models.AuditLog}
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/orders/{id}/history [get]
+func (h *AuditHandler) GetOrderHistory(c *gin.Context) {
+	principal := authentication.PrincipalFrom(c.Request.Context())
+	if principal != nil && principal.Admin {
+		h.history(c, "order")
+		return
+	}
+	var userID int
+	var err error
+	if principal != nil {
+		userID, err = strconv.Atoi(principal.UserID)
+	}
+	if principal == nil || err != nil {
+		problem.Abort(c, problem.Forbidden, "Order history can only be read for your own orders")
+		return
+	}
+	// The history of someone else's order reads as empty.
+	h.history(c, "order", repositories.ForOrdersOf(userID))
+}
+
+// GetCustomerHistory @Summary Get the history of a customer
+// @Description Get a page of the audit entries of one customer, newest first. Administrators only.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Success 200 {object} dto.BaseResponse{data=[]models.AuditLog}
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/customers/{id}/history [get]
+func (h *AuditHandler) GetCustomerHistory(c *gin.Context) {
+	h.history(c, "customer")
+}
+
+func (h *AuditHandler) history(c *gin.Context, entity string, scopes ...repositories.Scope) {
+	id, ok := pathID(c, h.logger, entity)
+	if !ok {
+		return
+	}
+
+	q, page, err := query.Parse(c.Request.URL.Query(), query.Spec{Sortable: auditListSpec.Sortable, DefaultSort: auditListSpec.DefaultSort})
+	if err != nil {
+		requestLog(c, h.logger).Warnf("invalid audit query: %v", err)
+		_ = c.Error(err)
+		return
+	}
+	q = q.Where(repositories.ForEntity(entity, id))
+	for _, scope := range scopes {
+		q = q.Where(scope)
+	}
+	h.list(c, q, page)
+}
+
+func (h *AuditHandler) list(c *gin.Context, q repositories.Query, page query.Page) {
+	entries, err := h.repo.List(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to get audit log: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	total, err := h.repo.Count(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to count audit log: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: entries, Message: "Audit log fetched successfully", StatusCode: http.StatusOK, Meta: dto.NewPagination(page.Number, page.Limit, total)})
+}
@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"backend/internal/dto"
+	"backend/internal/handlers"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/mocks"
+	"backend/pkg/authentication"
+	"backend/pkg/logging"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuditHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+	admin := &authentication.Principal{UserID: "1", Admin: true}
+
+	tests := []struct {
+		name           string
+		path           string
+		principal      *authentication.Principal
+		expectedStatus int
+		expectedScopes int
+	}{
+		{name: "Search", path: "/api/v1/audit?entity=order&id=3&action=update,delete", principal: admin, expectedStatus: http.StatusOK, expectedScopes: 3},
+		{name: "Unknown entity", path: "/api/v1/audit?entity=invoice", principal: admin, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Order history", path: "/api/v1/orders/3/history", principal: admin, expectedStatus: http.StatusOK, expectedScopes: 1},
+		{name: "Own order history", path: "/api/v1/orders/3/history", principal: &authentication.Principal{UserID: "7"}, expectedStatus: http.StatusOK, expectedScopes: 2},
+		{name: "Order history of a non-numeric user", path: "/api/v1/orders/3/history", principal: &authentication.Principal{UserID: "octocat"}, expectedStatus: http.StatusForbidden},
+		{name: "Customer history", path: "/api/v1/customers/3/history?page=2", principal: admin, expectedStatus: http.StatusOK, expectedScopes: 1},
+		{name: "Invalid ID", path: "/api/v1/orders/abc/history", principal: admin, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockAuditRepositoryImpl(ctrl)
+			if tt.expectedStatus == http.StatusOK {
+				entries := []models.AuditLog{{ID: 1, Entity: "order", EntityID: 3, Action: models.AuditUpdate, Actor: "wanjiru"}}
+				mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, q repositories.Query) ([]models.AuditLog, error) {
+					assert.Len(t, q.Scopes, tt.expectedScopes)
+					return entries, nil
+				})
+				mockRepo.EXPECT().Count(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			}
+			handler := handlers.NewAuditHandler(mockRepo, logger)
+
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(authentication.WithPrincipal(c.Request.Context(), tt.principal))
+			})
+			router.GET("/api/v1/audit", handler.GetAuditLog)
+			router.GET("/api/v1/orders/:id/history", handler.GetOrderHistory)
+			router.GET("/api/v1/customers/:id/history", handler.GetCustomerHistory)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response dto.BaseResponse
+				_ = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, "wanjiru", response.Data.([]interface{})[0].(map[string]interface{})["actor"])
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/internal/problem"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/markbates/goth/gothic"
@@ -51,6 +52,8 @@ func (h *AuthenticationHandler) SignIn(c *gin.Context) {
 // @Description Handle callback from Oauth
 // @Produce json
 // @Success 302 {string} string "Redirect"
+// @Failure 401 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/auth/callback [get]
 func (h *AuthenticationHandler) CallBack(c *gin.Context) {
 	q := c.Request.URL.Query()
@@ -58,16 +61,16 @@ func (h *AuthenticationHandler) CallBack(c *gin.Context) {
 	c.Request.URL.RawQuery = q.Encode()
 	user, err := gothic.CompleteUserAuth(c.Writer, c.Request)
 	if err != nil {
-		err = c.AbortWithError(http.StatusInternalServerError, err)
-		h.logger.Error(err)
+		requestLog(c, h.logger).Errorf("failed to complete sign-in: %v", err)
+		problem.Abort(c, problem.Unauthorized, "Sign-in with GitHub could not be completed")
 		return
 	}
 	session := sessions.Default(c)
 	session.Set("user", user)
 	err = session.Save()
 	if err != nil {
-		h.logger.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.logger).Errorf("failed to save session: %v", err)
+		problem.Abort(c, problem.Internal, "")
 		return
 	}
 
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/config"
+	"backend/internal/dto"
+	"backend/internal/metrics"
+	"backend/internal/models"
+	"backend/internal/problem"
+	"backend/internal/repositories"
+	"backend/internal/utils"
+	"backend/internal/validation"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// errBatchFailed rolls back an atomic batch once one of its items failed.
+var errBatchFailed = errors.New("batch item failed")
+
+const (
+	// batchItemBytes is the most a single encoded batch item may take up.
+	batchItemBytes = 2 << 10
+	// batchEnvelopeBytes leaves room for the rest of a batch body.
+	batchEnvelopeBytes = 1 << 10
+)
+
+// BatchBodyLimit is the largest batch body worth reading for maxItems items.
+// Larger ones are rejected before they are decoded.
+func BatchBodyLimit(maxItems int) int64 {
+	return int64(maxItems)*batchItemBytes + batchEnvelopeBytes
+}
+
+// BatchHandler creates many orders or customers in one request, so clients
+// syncing offline records pay for authentication and a transaction once.
+type BatchHandler struct {
+	tx       *repositories.TxManager
+	maxItems int
+	logger   *logrus.Logger
+}
+
+func NewBatchHandler(tx *repositories.TxManager, maxItems int, logger *logrus.Logger) *BatchHandler {
+	return &BatchHandler{tx: tx, maxItems: maxItems, logger: logger}
+}
+
+// CreateOrders @Summary Create orders in a batch
+// @Description Create up to BATCH_MAX_ITEMS orders. An atomic batch (the default) creates every order or none; a best_effort batch keeps the orders that could be created. The result lists every item in request order with its status, ID and error. 201 means every order was created, 207 that a best_effort batch was partly created; a failed atomic batch gets the status of its first failing item and creates nothing.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param batch body dto.BatchCreateOrdersRequest true "Orders"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request safe"
+// @Success 201 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Success 207 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 409 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Failure 413 {object} dto.Problem
+// @Failure 422 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Failure 429 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/orders:batch [post]
+func (h *BatchHandler) CreateOrders(c *gin.Context) {
+	req, ok := bindJSON[dto.BatchCreateOrdersRequest](c, h.logger, "create order batch")
+	if !ok {
+		return
+	}
+	result, ok := runBatch(c, h, req.Mode, req.Items, "create order", func(ctx context.Context, repos repositories.Repos, item dto.CreateOrderRequest) (interface{}, uint, error) {
+		order := models.Order{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UserId:    item.UserId,
+			Total:     item.Total,
+			Status:    orderStatus(item.Status),
+		}
+		if err := repos.Orders.Create(ctx, &order); err != nil {
+			return nil, 0, err
+		}
+		return order, order.ID, nil
+	})
+	if !ok {
+		return
+	}
+
+	if result.Succeeded > 0 {
+		metrics.OrdersCreated.Add(float64(result.Succeeded))
+		// One message for the whole batch rather than one per order.
+		err := utils.SendSMS(c.Request.Context(), config.AppConfig.SMSSandboxAPIKey, config.AppConfig.SMSSandboxUserName, "+254722123123", fmt.Sprintf("%d orders created successfully", result.Succeeded))
+		if err != nil {
+			requestLog(c, h.logger).Warnf("failed to create order batch: %v", err)
+		}
+	}
+	respondBatch(c, result, "orders")
+}
+
+// CreateCustomers @Summary Create customers in a batch
+// @Description Create up to BATCH_MAX_ITEMS customers. An atomic batch (the default) creates every customer or none; a best_effort batch keeps the customers that could be created. The result lists every item in request order with its status, ID and error. 201 means every customer was created, 207 that a best_effort batch was partly created; a failed atomic batch gets the status of its first failing item and creates nothing.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param batch body dto.BatchCreateCustomersRequest true "Customers"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request safe"
+// @Success 201 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Success 207 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 409 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Failure 413 {object} dto.Problem
+// @Failure 422 {object} dto.BaseResponse{data=dto.BatchResult}
+// @Failure 429 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/customers:batch [post]
+func (h *BatchHandler) CreateCustomers(c *gin.Context) {
+	req, ok := bindJSON[dto.BatchCreateCustomersRequest](c, h.logger, "create customer batch")
+	if !ok {
+		return
+	}
+	result, ok := runBatch(c, h, req.Mode, req.Items, "create customer", func(ctx context.Context, repos repositories.Repos, item dto.CustomerRequest) (interface{}, uint, error) {
+		customer := models.NewCustomer(item.Name, item.Code)
+		customer.Phone = item.Phone
+		if err := repos.Customers.Create(ctx, customer); err != nil {
+			return nil, 0, err
+		}
+		return customer, customer.ID, nil
+	})
+	if !ok {
+		return
+	}
+
+	if result.Succeeded > 0 {
+		metrics.CustomersCreated.Add(float64(result.Succeeded))
+	}
+	respondBatch(c, result, "customers")
+}
+
+// runBatch validates every item and creates the valid ones in a single
+// transaction, each in its own savepoint so a failed item leaves the
+// transaction usable for the next. An atomic batch stops at the first failure
+// and is rolled back; its remaining items are reported as failed dependencies.
+func runBatch[T any](c *gin.Context, h *BatchHandler, mode string, items []T, op string, create func(ctx context.Context, repos repositories.Repos, item T) (interface{}, uint, error)) (*dto.BatchResult, bool) {
+	if mode == "" {
+		mode = dto.BatchAtomic
+	}
+	if len(items) > h.maxItems {
+		fields := validation.Errors{{Field: "items", Code: "max", Message: fmt.Sprintf("items must be at most %d", h.maxItems)}}
+		requestLog(c, h.logger).Warnf("Invalid request to %s batch: %v", op, fields)
+		_ = c.Error(apperrors.Validation(op+" batch", fields))
+		return nil, false
+	}
+
+	// Items are checked up front, so an atomic batch with an invalid item
+	// never touches the database.
+	invalid := make([]error, len(items))
+	anyInvalid := false
+	for i := range items {
+		if err := binding.Validator.ValidateStruct(&items[i]); err != nil {
+			if fields, ok := validation.Fields(err); ok {
+				err = fields
+			}
+			invalid[i] = apperrors.Validation(op, err)
+			anyInvalid = true
+		}
+	}
+
+	ctx := c.Request.Context()
+	var result *dto.BatchResult
+	run := func(repos repositories.Repos) error {
+		// Rebuilt on every attempt, since the transaction may be replayed.
+		result = &dto.BatchResult{Mode: mode, Items: make([]dto.BatchItemResult, len(items))}
+		atomic := mode == dto.BatchAtomic
+		failed := false
+		for i, item := range items {
+			res := &result.Items[i]
+			res.Index = i
+			err := invalid[i]
+			if err == nil && atomic && (failed || anyInvalid) {
+				continue
+			}
+			if err == nil {
+				err = repos.WithinTx(ctx, func(repos repositories.Repos) error {
+					var err error
+					res.Data, res.ID, err = create(ctx, repos, item)
+					return err
+				})
+			}
+			if err != nil {
+				requestLog(c, h.logger).Warnf("failed to %s %d of batch: %v", op, i, err)
+				res.Error = problem.FromError(c, err)
+				res.Status = res.Error.Status
+				failed = true
+				continue
+			}
+			res.Status = http.StatusCreated
+		}
+
+		if atomic && failed {
+			return errBatchFailed
+		}
+		return nil
+	}
+
+	var err error
+	if mode == dto.BatchAtomic && anyInvalid {
+		// Only reports the invalid items; no repository is used.
+		err = run(repositories.Repos{})
+	} else {
+		err = h.tx.WithinTx(ctx, run)
+	}
+	if err != nil && !errors.Is(err, errBatchFailed) {
+		requestLog(c, h.logger).Errorf("failed to %s batch: %v", op, err)
+		_ = c.Error(err)
+		return nil, false
+	}
+
+	for i := range result.Items {
+		res := &result.Items[i]
+		switch {
+		case res.Status == 0:
+			res.Error = problem.New(c, problem.FailedDependency, fmt.Sprintf("item %d was not written because the batch was rolled back", i))
+			res.Status = res.Error.Status
+		case errors.Is(err, errBatchFailed) && res.Error == nil:
+			// Created inside the transaction, then rolled back with it.
+			res.Data, res.ID = nil, 0
+			res.Error = problem.New(c, problem.FailedDependency, fmt.Sprintf("item %d was rolled back with the batch", i))
+			res.Status = res.Error.Status
+		}
+		if res.Error == nil {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, true
+}
+
+// respondBatch answers 201 when every item was created, 207 when a best-effort
+// batch was partly created and otherwise the status of the first failed item.
+func respondBatch(c *gin.Context, result *dto.BatchResult, entity string) {
+	status := http.StatusCreated
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+		if result.Mode == dto.BatchAtomic {
+			for _, item := range result.Items {
+				if item.Status != http.StatusFailedDependency {
+					status = item.Status
+					break
+				}
+			}
+		}
+	}
+	respond(c, status, result, fmt.Sprintf("Created %d of %d %s", result.Succeeded, len(result.Items), entity))
+}
@@ -0,0 +1,154 @@
+package handlers_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/dto"
+	"backend/internal/handlers"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/database"
+	"backend/pkg/logging"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+
+	tests := []struct {
+		name            string
+		path            string
+		body            string
+		expectedStatus  int
+		expectedItems   []int
+		expectedCreated int64
+		expectedProblem bool
+	}{
+		{
+			name:            "Atomic orders",
+			path:            "/api/v1/orders:batch",
+			body:            `{"items": [{"product_id": 1, "quantity": 2, "user_id": 1}, {"product_id": 2, "quantity": 1, "user_id": 1}]}`,
+			expectedStatus:  http.StatusCreated,
+			expectedItems:   []int{http.StatusCreated, http.StatusCreated},
+			expectedCreated: 2,
+		},
+		{
+			name:            "Atomic orders with an invalid item",
+			path:            "/api/v1/orders:batch",
+			body:            `{"items": [{"product_id": 1, "quantity": 2, "user_id": 1}, {"product_id": 2, "quantity": 0, "user_id": 1}]}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedItems:   []int{http.StatusFailedDependency, http.StatusUnprocessableEntity},
+			expectedCreated: 0,
+		},
+		{
+			name:            "Atomic customers rolled back on conflict",
+			path:            "/api/v1/customers:batch",
+			body:            `{"mode": "atomic", "items": [{"name": "Amina", "code": "C1"}, {"name": "Baraka", "code": "C1"}, {"name": "Chege", "code": "C3"}]}`,
+			expectedStatus:  http.StatusConflict,
+			expectedItems:   []int{http.StatusFailedDependency, http.StatusConflict, http.StatusFailedDependency},
+			expectedCreated: 0,
+		},
+		{
+			name:            "Best-effort customers",
+			path:            "/api/v1/customers:batch",
+			body:            `{"mode": "best_effort", "items": [{"name": "Amina", "code": "C1"}, {"name": "Baraka", "code": "C1"}, {"name": "Chege", "code": "c3"}, {"name": "Dalila", "code": "C4"}]}`,
+			expectedStatus:  http.StatusMultiStatus,
+			expectedItems:   []int{http.StatusCreated, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusCreated},
+			expectedCreated: 2,
+		},
+		{
+			name:            "Too many items",
+			path:            "/api/v1/orders:batch",
+			body:            `{"items": [{"product_id": 1, "quantity": 1, "user_id": 1}, {"product_id": 1, "quantity": 1, "user_id": 1}, {"product_id": 1, "quantity": 1, "user_id": 1}, {"product_id": 1, "quantity": 1, "user_id": 1}, {"product_id": 1, "quantity": 1, "user_id": 1}]}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedProblem: true,
+		},
+		{
+			name:            "Body far larger than the item limit",
+			path:            "/api/v1/orders:batch",
+			body:            `{"items": [` + strings.Repeat(`{"product_id": 1, "quantity": 1, "user_id": 1},`, 200) + `{"product_id": 1, "quantity": 1, "user_id": 1}]}`,
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedProblem: true,
+		},
+		{
+			name:            "Unknown mode",
+			path:            "/api/v1/customers:batch",
+			body:            `{"mode": "eventually", "items": [{"name": "Amina", "code": "C1"}]}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedProblem: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openBatchDB(t)
+			handler := handlers.NewBatchHandler(repositories.NewTxManager(db, logger), 4, logger)
+
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.Use(middleware.BodyLimit(handlers.BatchBodyLimit(4)))
+			router.POST("/api/v1/orders:batch", handler.CreateOrders)
+			router.POST("/api/v1/customers:batch", handler.CreateCustomers)
+
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedProblem {
+				var response dto.Problem
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedStatus == http.StatusUnprocessableEntity, len(response.Errors) > 0)
+				return
+			}
+
+			var response struct {
+				Data dto.BatchResult `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			var statuses []int
+			for i, item := range response.Data.Items {
+				assert.Equal(t, i, item.Index)
+				assert.Equal(t, item.Status == http.StatusCreated, item.ID != 0)
+				assert.Equal(t, item.Status != http.StatusCreated, item.Error != nil)
+				statuses = append(statuses, item.Status)
+			}
+			assert.Equal(t, tt.expectedItems, statuses)
+			assert.Equal(t, int(tt.expectedCreated), response.Data.Succeeded)
+
+			var created int64
+			model := interface{}(&models.Order{})
+			if tt.path == "/api/v1/customers:batch" {
+				model = &models.Customer{}
+			}
+			require.NoError(t, db.Model(model).Count(&created).Error)
+			assert.Equal(t, tt.expectedCreated, created)
+		})
+	}
+}
+
+func openBatchDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	logger := logging.GetLogger()
+	db, err := database.Open(config.Config{DBDriver: database.DriverSQLite, DatabaseURL: filepath.Join(t.TempDir(), "batch.db")}, logger)
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate(db, logger))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -1,16 +1,31 @@
 package handlers
 
 import (
+	"backend/internal/apperrors"
 	"backend/internal/dto"
+	"backend/internal/metrics"
 	"backend/internal/models"
+	"backend/internal/query"
 	"backend/internal/repositories"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"net/http"
-	"strconv"
 )
 
+// customerListSpec whitelists the customer columns list endpoints may sort and filter on.
+var customerListSpec = query.Spec{
+	Sortable:    []string{"id", "name", "code", "created_at", "updated_at"},
+	DefaultSort: "id",
+	Filters: map[string]query.Filter{
+		"name":           {Column: "name", Parse: query.String},
+		"code":           {Column: "code", Parse: query.String, Multiple: true},
+		"created_after":  {Column: "created_at", Operator: query.AtLeast, Parse: query.Time},
+		"created_before": {Column: "created_at", Operator: query.AtMost, Parse: query.Time},
+	},
+}
+
 type CustomerHandler struct {
 	repo   repositories.CustomerRepositoryImpl
 	logger *logrus.Logger
@@ -19,7 +34,13 @@ type CustomerHandler struct {
 type CustomerHandlerImpl interface {
 	CreateCustomer(c *gin.Context)
 	UpdateCustomer(c *gin.Context)
+	PatchCustomer(c *gin.Context)
 	GetAllCustomers(c *gin.Context)
+	GetCustomerByID(c *gin.Context)
+	DeleteCustomer(c *gin.Context)
+	GetTrashedCustomers(c *gin.Context)
+	RestoreCustomer(c *gin.Context)
+	PurgeCustomer(c *gin.Context)
 }
 
 func NewCustomerHandler(repo repositories.CustomerRepositoryImpl, logger *logrus.Logger) *CustomerHandler {
@@ -31,105 +52,391 @@ func NewCustomerHandler(repo repositories.CustomerRepositoryImpl, logger *logrus
 // @Tags Customers
 // @Accept json
 // @Produce json
-// @Param name body string true "Customer name"
-// @Param code body string true "Customer code"
+// @Param customer body dto.CustomerRequest true "Customer"
 // @Success 201 {object} dto.BaseResponse
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
 // @Router /api/v1/customers [post]
 func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
-	var customer models.Customer
-	if err := c.ShouldBindJSON(&customer); err != nil {
-		h.logger.Warnf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{
-			Data:       nil,
-			Message:    fmt.Sprintf("Failed to bind JSON: %v", err),
-			StatusCode: http.StatusBadRequest,
-		})
-	}
-	if err := h.repo.Create(&customer); err != nil {
-		h.logger.Errorf("Failed to create customer: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{
-			Data:       nil,
-			Message:    fmt.Sprintf("Failed to create customer: %v", err),
-			StatusCode: http.StatusInternalServerError,
-		})
-	}
-
-	h.logger.Infof("Created customer with ID: %d", customer.ID)
-
-	c.JSON(http.StatusCreated, dto.BaseResponse{
-		Data:       customer,
-		Message:    fmt.Sprintf("Successfully created customer with ID: %d", customer.ID),
-		StatusCode: http.StatusCreated,
-	})
+	req, ok := bindJSON[dto.CustomerRequest](c, h.logger, "create customer")
+	if !ok {
+		return
+	}
+
+	customer := models.NewCustomer(req.Name, req.Code)
+	customer.Phone = req.Phone
+	if err := h.repo.Create(c.Request.Context(), customer); err != nil {
+		requestLog(c, h.logger).Errorf("Failed to create customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	metrics.CustomersCreated.Inc()
+	requestLog(c, h.logger).Infof("Created customer with ID: %d", customer.ID)
+	respond(c, http.StatusCreated, customer, fmt.Sprintf("Successfully created customer with ID: %d", customer.ID))
 }
 
 // UpdateCustomer @Summary Update a customer
-// @Description Update an existing customer
+// @Description Update an existing customer. If-Match must carry the customer's current ETag.
 // @Tags Customers
 // @Accept json
 // @Produce json
 // @Param id path int true "Customer ID"
-// @Param name body string true "Customer name"
-// @Param code body string true "Customer code"
+// @Param If-Match header string true "ETag of the customer being replaced, or * for any version"
+// @Param customer body dto.CustomerRequest true "Customer"
 // @Success 200 {object} dto.BaseResponse
+// @Header 200 {string} ETag "Version of the updated customer"
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 428 {object} dto.Problem
 // @Router /api/v1/customers/{id} [put]
 func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	var customer models.Customer
-	if err := c.ShouldBindJSON(&customer); err != nil {
-		h.logger.Warnf("Invalid customer ID: %s", c.Param("id"))
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{
-			Data:       nil,
-			Message:    fmt.Sprintf("Invalid customer ID: %s", c.Param("id")),
-			StatusCode: http.StatusBadRequest,
-		})
-	}
-
-	customer.ID = id
-	if err := h.repo.Update(&customer); err != nil {
-		h.logger.Warnf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{
-			Data:       nil,
-			Message:    fmt.Sprintf("Failed to bind JSON: %v", err),
-			StatusCode: http.StatusInternalServerError,
-		})
-	}
-
-	h.logger.Infof("Updated customer with ID: %d", customer.ID)
-	c.JSON(http.StatusOK, dto.BaseResponse{
-		Data:       customer,
-		Message:    fmt.Sprintf("Successfully updated customer with ID: %d", customer.ID),
-		StatusCode: http.StatusOK,
-	})
+	id, ok := pathID(c, h.logger, "customer")
+	if !ok {
+		return
+	}
+	version, wildcard, ok := ifMatch(c, "update customer")
+	if !ok {
+		return
+	}
+	req, ok := bindJSON[dto.CustomerRequest](c, h.logger, "update customer")
+	if !ok {
+		return
+	}
+
+	customer, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Failed to get customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+	if !wildcard {
+		customer.Version = version
+	}
+
+	customer.Name = req.Name
+	customer.Code = req.Code
+	customer.Phone = req.Phone
+	if err := h.repo.Update(c.Request.Context(), customer); err != nil {
+		requestLog(c, h.logger).Warnf("Failed to update customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLog(c, h.logger).Infof("Updated customer with ID: %d", customer.ID)
+	c.Header("ETag", etag(customer.Version))
+	respond(c, http.StatusOK, customer, fmt.Sprintf("Successfully updated customer with ID: %d", customer.ID))
+}
+
+// PatchCustomer @Summary Partially update a customer
+// @Description Change only the fields present in an RFC 7396 merge patch (application/merge-patch+json)
+// @Description or an RFC 6902 JSON Patch (application/json-patch+json). If-Match must carry the customer's current ETag.
+// @Tags Customers
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param If-Match header string true "ETag of the customer being patched, or * for any version"
+// @Param patch body dto.CustomerPatch true "Fields to change"
+// @Success 200 {object} dto.BaseResponse
+// @Header 200 {string} ETag "Version of the patched customer"
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 415 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 428 {object} dto.Problem
+// @Router /api/v1/customers/{id} [patch]
+func (h *CustomerHandler) PatchCustomer(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "customer")
+	if !ok {
+		return
+	}
+
+	version, wildcard, ok := ifMatch(c, "patch customer")
+	if !ok {
+		return
+	}
+
+	customer, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Failed to get customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+	if !wildcard && version != customer.Version {
+		_ = c.Error(apperrors.PreconditionFailed("patch customer", errors.New("customer was modified by another request")))
+		return
+	}
+
+	patch, changed, ok := applyPatch(c, "patch customer", dto.CustomerPatch{Name: customer.Name, Code: customer.Code, Phone: customer.Phone})
+	if !ok {
+		return
+	}
+
+	if len(changed) > 0 {
+		customer.Name = patch.Name
+		customer.Code = patch.Code
+		customer.Phone = patch.Phone
+		if err := h.repo.Patch(c.Request.Context(), customer, changed); err != nil {
+			requestLog(c, h.logger).Warnf("Failed to patch customer: %v", err)
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	requestLog(c, h.logger).Infof("Patched customer with ID: %d", customer.ID)
+	c.Header("ETag", etag(customer.Version))
+	respond(c, http.StatusOK, customer, fmt.Sprintf("Successfully updated customer with ID: %d", customer.ID))
+}
+
+// GetCustomerByID @Summary Get a customer by ID
+// @Description Get a customer by ID. The ETag response header identifies its version; send it back
+// @Description in If-None-Match to get 304 while it is unchanged, or in If-Match to update or delete it.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} dto.BaseResponse
+// @Success 304 "Not Modified"
+// @Header 200,304 {string} ETag "Version of the customer"
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Router /api/v1/customers/{id} [get]
+func (h *CustomerHandler) GetCustomerByID(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "customer")
+	if !ok {
+		return
+	}
+
+	customer, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Failed to get customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	if notModified(c, customer.Version) {
+		return
+	}
+	respond(c, http.StatusOK, customer, fmt.Sprintf("Successfully retrieved customer with ID: %d", customer.ID))
+}
+
+// DeleteCustomer @Summary Delete a customer
+// @Description Move a customer to the trash. If-Match must carry the customer's current ETag.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param If-Match header string true "ETag of the customer being deleted, or * for any version"
+// @Success 204 {object} dto.BaseResponse
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 428 {object} dto.Problem
+// @Router /api/v1/customers/{id} [delete]
+func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "customer")
+	if !ok {
+		return
+	}
+
+	version, wildcard, ok := ifMatch(c, "delete customer")
+	if !ok {
+		return
+	}
+
+	var err error
+	if wildcard {
+		err = h.repo.Delete(c.Request.Context(), id)
+	} else {
+		err = h.repo.DeleteIfVersion(c.Request.Context(), id, version)
+	}
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Failed to delete customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLog(c, h.logger).Infof("Deleted customer with ID: %d", id)
+	respond(c, http.StatusNoContent, nil, fmt.Sprintf("Successfully deleted customer with ID: %d", id))
 }
 
 // GetAllCustomers @Summary Get all customers
-// @Description Get all customers
+// @Description Get a page of customers, optionally filtered and sorted
 // @Tags Customers
 // @Accept json
 // @Produce json
-// @Success 200 {array} dto.BaseResponse
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (e.g. name)"
+// @Param name query string false "Filter by exact name"
+// @Param code query string false "Filter by code; comma separated for several"
+// @Param created_after query string false "Only customers created at or after this RFC 3339 time or date"
+// @Param created_before query string false "Only customers created at or before this RFC 3339 time or date"
+// @Success 200 {object} dto.BaseResponse
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
 // @Router /api/v1/customers [get]
 func (h *CustomerHandler) GetAllCustomers(c *gin.Context) {
-	customers, err := h.repo.GetAll()
+	q, page, err := query.Parse(c.Request.URL.Query(), customerListSpec)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Invalid customer query: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	customers, err := h.repo.List(c.Request.Context(), q)
 	if err != nil {
-		h.logger.Errorf("Failed to get all customers: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{
-			Data:       nil,
-			Message:    fmt.Sprintf("Failed to get all customers: %v", err),
-			StatusCode: http.StatusInternalServerError,
-		})
+		requestLog(c, h.logger).Errorf("Failed to get all customers: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	total, err := h.repo.Count(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Errorf("Failed to count customers: %v", err)
+		_ = c.Error(err)
+		return
 	}
 
 	c.JSON(http.StatusOK, dto.BaseResponse{
 		Data:       customers,
 		Message:    fmt.Sprintf("Customers retried successfully"),
 		StatusCode: http.StatusOK,
+		Meta:       dto.NewPagination(page.Number, page.Limit, total),
 	})
 }
+
+// GetTrashedCustomers @Summary List deleted customers
+// @Description Get a page of soft-deleted customers, most recently deleted first
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (default -deleted_at)"
+// @Param name query string false "Filter by exact name"
+// @Param code query string false "Filter by code; comma separated for several"
+// @Param deleted_after query string false "Only customers deleted at or after this RFC 3339 time or date"
+// @Param deleted_before query string false "Only customers deleted at or before this RFC 3339 time or date"
+// @Success 200 {object} dto.BaseResponse
+// @Security ApiKeyAuth
+// @Failure 401 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Router /api/v1/customers/trash [get]
+func (h *CustomerHandler) GetTrashedCustomers(c *gin.Context) {
+	q, page, err := query.Parse(c.Request.URL.Query(), customerListSpec.Trash())
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Invalid customer query: %v", err)
+		_ = c.Error(err)
+		return
+	}
+	q = q.Where(repositories.Trashed)
+
+	customers, err := h.repo.List(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Errorf("Failed to get deleted customers: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	total, err := h.repo.Count(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Errorf("Failed to count deleted customers: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Data:       customers,
+		Message:    "Deleted customers retrieved successfully",
+		StatusCode: http.StatusOK,
+		Meta:       dto.NewPagination(page.Number, page.Limit, total),
+	})
+}
+
+// RestoreCustomer @Summary Restore a deleted customer
+// @Description Move a soft-deleted customer out of the trash
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} dto.BaseResponse
+// @Header 200 {string} ETag "Version of the restored customer"
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Router /api/v1/customers/{id}/restore [post]
+func (h *CustomerHandler) RestoreCustomer(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "customer")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Restore(c.Request.Context(), id); err != nil {
+		requestLog(c, h.logger).Warnf("Failed to restore customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	customer, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("Failed to get customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLog(c, h.logger).Infof("Restored customer with ID: %d", customer.ID)
+	c.Header("ETag", etag(customer.Version))
+	respond(c, http.StatusOK, customer, fmt.Sprintf("Successfully restored customer with ID: %d", customer.ID))
+}
+
+// PurgeCustomer @Summary Permanently delete a customer
+// @Description Permanently delete a customer that is already in the trash. Administrators only.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 204 {object} dto.BaseResponse
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Router /api/v1/customers/trash/{id} [delete]
+func (h *CustomerHandler) PurgeCustomer(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "customer")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Purge(c.Request.Context(), id); err != nil {
+		requestLog(c, h.logger).Warnf("Failed to purge customer: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLog(c, h.logger).Infof("Purged customer with ID: %d", id)
+	respond(c, http.StatusNoContent, nil, fmt.Sprintf("Successfully purged customer with ID: %d", id))
+}
@@ -1,15 +1,20 @@
 package handlers
 
 import (
+	"backend/internal/apperrors"
 	"backend/internal/dto"
+	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/problem"
 	"backend/mocks"
 	"backend/pkg/logging"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -27,7 +32,7 @@ func TestCreateCustomer(t *testing.T) {
 	customer := &models.Customer{Name: "Test Customer", Code: "TST123"}
 	customerJSON, _ := json.Marshal(customer)
 
-	mockRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -45,6 +50,52 @@ func TestCreateCustomer(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, recorder.Code)
 }
 
+func TestCreateCustomer_InvalidCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logging.GetLogger()
+	mockRepo := mocks.NewMockCustomerRepositoryImpl(ctrl)
+	handler := NewCustomerHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.POST("/api/v1/customers", handler.CreateCustomer)
+
+	req, _ := http.NewRequest("POST", "/api/v1/customers", bytes.NewBufferString(`{"name":"Test Customer","code":"tst 123"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestCreateCustomer_DuplicateCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logging.GetLogger()
+	mockRepo := mocks.NewMockCustomerRepositoryImpl(ctrl)
+	handler := NewCustomerHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.POST("/api/v1/customers", handler.CreateCustomer)
+
+	mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(apperrors.Conflict("create customer", errors.New("duplicated key not allowed")))
+
+	req, _ := http.NewRequest("POST", "/api/v1/customers", bytes.NewBufferString(`{"name":"Test Customer","code":"TST123"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
 func TestUpdateCustomer(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctrl := gomock.NewController(t)
@@ -59,22 +110,54 @@ func TestUpdateCustomer(t *testing.T) {
 	router.PUT("/api/v1/customers/:id", handler.UpdateCustomer)
 
 	// Test case: Successful update
-	customer := &models.Customer{ID: 1, Name: "John Doe", Code: "C123"}
-	mockRepo.EXPECT().Update(gomock.Any()).Return(nil)
+	customer := &models.Customer{Model: gorm.Model{ID: 1}, Name: "John Doe", Code: "C123"}
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&models.Customer{Model: gorm.Model{ID: 1}, Versioned: models.Versioned{Version: 2}}, nil)
+	mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
 
 	reqBody, _ := json.Marshal(customer)
 	req, _ := http.NewRequest("PUT", "/api/v1/customers/1", bytes.NewReader(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 
 	var response dto.BaseResponse
 	_ = json.Unmarshal(w.Body.Bytes(), &response)
 }
 
+func TestPatchCustomer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logging.GetLogger()
+
+	mockRepo := mocks.NewMockCustomerRepositoryImpl(ctrl)
+	handler := NewCustomerHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.PATCH("/api/v1/customers/:id", handler.PatchCustomer)
+
+	stored := &models.Customer{Model: gorm.Model{ID: 1}, Name: "John Doe", Code: "C123", Versioned: models.Versioned{Version: 1}}
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
+	mockRepo.EXPECT().Patch(gomock.Any(), stored, []string{"name"}).Return(nil)
+
+	req, _ := http.NewRequest("PATCH", "/api/v1/customers/1", bytes.NewBufferString(`{"name":"Jane Doe"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Jane Doe", stored.Name)
+	assert.Equal(t, "C123", stored.Code)
+}
+
 func TestGetAllCustomers(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctrl := gomock.NewController(t)
@@ -90,10 +173,11 @@ func TestGetAllCustomers(t *testing.T) {
 
 	// Test case: Successful fetch
 	customers := []models.Customer{
-		{ID: 1, Name: "John Doe", Code: "C123"},
-		{ID: 2, Name: "Jane Doe", Code: "C124"},
+		{Model: gorm.Model{ID: 1}, Name: "John Doe", Code: "C123"},
+		{Model: gorm.Model{ID: 2}, Name: "Jane Doe", Code: "C124"},
 	}
-	mockRepo.EXPECT().GetAll().Return(customers, nil)
+	mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(customers, nil)
+	mockRepo.EXPECT().Count(gomock.Any(), gomock.Any()).Return(int64(len(customers)), nil)
 
 	req, _ := http.NewRequest("GET", "/api/v1/customers", nil)
 
@@ -107,3 +191,285 @@ func TestGetAllCustomers(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 }
+
+func TestGetCustomerByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logging.GetLogger()
+	mockRepo := mocks.NewMockCustomerRepositoryImpl(ctrl)
+	handler := NewCustomerHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.GET("/api/v1/customers/:id", handler.GetCustomerByID)
+
+	stored := &models.Customer{Model: gorm.Model{ID: 1}, Name: "John Doe", Code: "C123", Versioned: models.Versioned{Version: 3}}
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil).Times(2)
+	mockRepo.EXPECT().GetByID(gomock.Any(), 42).Return(nil, apperrors.NotFound("get customer by ID", errors.New("record not found")))
+
+	req, _ := http.NewRequest("GET", "/api/v1/customers/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	var response dto.BaseResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "C123", response.Data.(map[string]interface{})["code"])
+
+	req, _ = http.NewRequest("GET", "/api/v1/customers/1", nil)
+	req.Header.Set("If-None-Match", `"3"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/customers/42", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/customers/abc", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteCustomer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logging.GetLogger()
+	mockRepo := mocks.NewMockCustomerRepositoryImpl(ctrl)
+	handler := NewCustomerHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.DELETE("/api/v1/customers/:id", handler.DeleteCustomer)
+
+	mockRepo.EXPECT().DeleteIfVersion(gomock.Any(), 1, uint(2)).Return(nil)
+	mockRepo.EXPECT().DeleteIfVersion(gomock.Any(), 2, uint(1)).Return(apperrors.PreconditionFailed("delete customer", errors.New("customer was modified by another request")))
+	mockRepo.EXPECT().Delete(gomock.Any(), 3).Return(nil)
+
+	tests := []struct {
+		path    string
+		ifMatch string
+		status  int
+	}{
+		{"/api/v1/customers/1", `"2"`, http.StatusNoContent},
+		{"/api/v1/customers/2", `"1"`, http.StatusPreconditionFailed},
+		{"/api/v1/customers/3", "*", http.StatusNoContent},
+		{"/api/v1/customers/4", "", http.StatusPreconditionRequired},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("DELETE", tt.path, nil)
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tt.status, w.Code, tt.path)
+	}
+}
+
+func TestCustomerHandler_Failures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		errNotFound = apperrors.NotFound("get customer by ID", errors.New("record not found"))
+		errConflict = apperrors.Conflict("create customer", errors.New("duplicated key not allowed"))
+		errStale    = apperrors.PreconditionFailed("update customer", errors.New("customer was modified by another request"))
+		errDown     = apperrors.Unavailable("list customers", errors.New("connection refused"))
+	)
+	stored := func() *models.Customer {
+		return &models.Customer{Model: gorm.Model{ID: 1}, Name: "John Doe", Code: "C123", Versioned: models.Versioned{Version: 2}}
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		contentType string
+		ifMatch     string
+		expect      func(repo *mocks.MockCustomerRepositoryImpl)
+		status      int
+	}{
+		{name: "create with malformed JSON", method: "POST", path: "/api/v1/customers", body: `{"name":`, status: http.StatusBadRequest},
+		{name: "create without name", method: "POST", path: "/api/v1/customers", body: `{"code":"C123"}`, status: http.StatusUnprocessableEntity},
+		{name: "create with invalid phone", method: "POST", path: "/api/v1/customers", body: `{"name":"John Doe","code":"C123","phone":"12345"}`, status: http.StatusUnprocessableEntity},
+		{name: "create with invalid code", method: "POST", path: "/api/v1/customers", body: `{"name":"John Doe","code":"c 123"}`, status: http.StatusUnprocessableEntity},
+		{
+			name: "create with duplicate code", method: "POST", path: "/api/v1/customers", body: `{"name":"John Doe","code":"C123"}`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errConflict)
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "create while database is down", method: "POST", path: "/api/v1/customers", body: `{"name":"John Doe","code":"C123"}`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errDown)
+			},
+			status: http.StatusServiceUnavailable,
+		},
+		{name: "update with invalid ID", method: "PUT", path: "/api/v1/customers/abc", body: `{"name":"John Doe","code":"C123"}`, ifMatch: `"2"`, status: http.StatusBadRequest},
+		{name: "update without If-Match", method: "PUT", path: "/api/v1/customers/1", body: `{"name":"John Doe","code":"C123"}`, status: http.StatusPreconditionRequired},
+		{name: "update with weak If-Match", method: "PUT", path: "/api/v1/customers/1", body: `{"name":"John Doe","code":"C123"}`, ifMatch: `W/"2"`, status: http.StatusPreconditionFailed},
+		{name: "update with malformed JSON", method: "PUT", path: "/api/v1/customers/1", body: `{"name":`, ifMatch: `"2"`, status: http.StatusBadRequest},
+		{name: "update with invalid code", method: "PUT", path: "/api/v1/customers/1", body: `{"name":"John Doe","code":"c 123"}`, ifMatch: `"2"`, status: http.StatusUnprocessableEntity},
+		{
+			name: "update missing customer", method: "PUT", path: "/api/v1/customers/1", body: `{"name":"John Doe","code":"C123"}`, ifMatch: `"2"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "update stale customer", method: "PUT", path: "/api/v1/customers/1", body: `{"name":"John Doe","code":"C123"}`, ifMatch: `"1"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(stored(), nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errStale)
+			},
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name: "update to duplicate code", method: "PUT", path: "/api/v1/customers/1", body: `{"name":"John Doe","code":"C124"}`, ifMatch: `"2"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(stored(), nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errConflict)
+			},
+			status: http.StatusConflict,
+		},
+		{name: "patch with invalid ID", method: "PATCH", path: "/api/v1/customers/abc", body: `{"name":"Jane Doe"}`, ifMatch: `"2"`, status: http.StatusBadRequest},
+		{name: "patch without If-Match", method: "PATCH", path: "/api/v1/customers/1", body: `{"name":"Jane Doe"}`, status: http.StatusPreconditionRequired},
+		{
+			name: "patch missing customer", method: "PATCH", path: "/api/v1/customers/1", body: `{"name":"Jane Doe"}`, ifMatch: `"2"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "patch stale customer", method: "PATCH", path: "/api/v1/customers/1", body: `{"name":"Jane Doe"}`, ifMatch: `"1"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name: "patch with unsupported content type", method: "PATCH", path: "/api/v1/customers/1", body: `name=Jane`, contentType: "application/x-www-form-urlencoded", ifMatch: `"2"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "patch with invalid code", method: "PATCH", path: "/api/v1/customers/1", body: `{"code":"c 123"}`, ifMatch: `"2"`,
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			status: http.StatusUnprocessableEntity,
+		},
+		{name: "get with invalid ID", method: "GET", path: "/api/v1/customers/abc", status: http.StatusBadRequest},
+		{
+			name: "get missing customer", method: "GET", path: "/api/v1/customers/1",
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{name: "delete with invalid ID", method: "DELETE", path: "/api/v1/customers/abc", ifMatch: "*", status: http.StatusBadRequest},
+		{name: "delete without If-Match", method: "DELETE", path: "/api/v1/customers/1", status: http.StatusPreconditionRequired},
+		{
+			name: "delete missing customer", method: "DELETE", path: "/api/v1/customers/1", ifMatch: "*",
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().Delete(gomock.Any(), 1).Return(errNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{name: "list with unknown sort field", method: "GET", path: "/api/v1/customers?sort=secret", status: http.StatusUnprocessableEntity},
+		{
+			name: "list while database is down", method: "GET", path: "/api/v1/customers",
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errDown)
+			},
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name: "list when count fails", method: "GET", path: "/api/v1/customers",
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]models.Customer{}, nil)
+				repo.EXPECT().Count(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("boom"))
+			},
+			status: http.StatusInternalServerError,
+		},
+		{name: "list trash with unknown filter value", method: "GET", path: "/api/v1/customers/trash?deleted_after=yesterday", status: http.StatusUnprocessableEntity},
+		{name: "restore with invalid ID", method: "POST", path: "/api/v1/customers/abc/restore", status: http.StatusBadRequest},
+		{
+			name: "restore over a reused code", method: "POST", path: "/api/v1/customers/1/restore",
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().Restore(gomock.Any(), 1).Return(errConflict)
+			},
+			status: http.StatusConflict,
+		},
+		{name: "purge with invalid ID", method: "DELETE", path: "/api/v1/customers/trash/abc", status: http.StatusBadRequest},
+		{
+			name: "purge customer not in trash", method: "DELETE", path: "/api/v1/customers/trash/1",
+			expect: func(repo *mocks.MockCustomerRepositoryImpl) {
+				repo.EXPECT().Purge(gomock.Any(), 1).Return(errNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			logger := logging.GetLogger()
+			mockRepo := mocks.NewMockCustomerRepositoryImpl(ctrl)
+			if tt.expect != nil {
+				tt.expect(mockRepo)
+			}
+			handler := NewCustomerHandler(mockRepo, logger)
+
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.POST("/api/v1/customers", handler.CreateCustomer)
+			router.GET("/api/v1/customers", handler.GetAllCustomers)
+			router.GET("/api/v1/customers/trash", handler.GetTrashedCustomers)
+			router.DELETE("/api/v1/customers/trash/:id", handler.PurgeCustomer)
+			router.GET("/api/v1/customers/:id", handler.GetCustomerByID)
+			router.PUT("/api/v1/customers/:id", handler.UpdateCustomer)
+			router.PATCH("/api/v1/customers/:id", handler.PatchCustomer)
+			router.DELETE("/api/v1/customers/:id", handler.DeleteCustomer)
+			router.POST("/api/v1/customers/:id/restore", handler.RestoreCustomer)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			contentType := tt.contentType
+			if contentType == "" && tt.body != "" {
+				contentType = "application/json"
+			}
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			// Exactly one problem document must have been written.
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			var response dto.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+			assert.Equal(t, tt.status, response.Status)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/problem"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag renders the entity tag of a versioned resource.
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ifMatch reads the version a write is conditional on from the If-Match
+// header. wildcard is true for "If-Match: *", which matches whatever version is
+// current. When ok is false a response has already been produced: 428 if the
+// header is missing, 412 if it cannot match any version.
+func ifMatch(c *gin.Context, op string) (version uint, wildcard bool, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		problem.Abort(c, problem.PreconditionRequired, "If-Match header with the resource ETag is required")
+		return 0, false, false
+	}
+	if header == "*" {
+		return 0, true, true
+	}
+
+	// If-Match uses strong comparison, so weak tags and lists of several tags
+	// never identify the single version the write applies to.
+	unquoted, err := strconv.Unquote(header)
+	if err == nil {
+		if parsed, err := strconv.ParseUint(unquoted, 10, 0); err == nil && parsed > 0 {
+			return uint(parsed), false, true
+		}
+	}
+	_ = c.Error(apperrors.PreconditionFailed(op, errors.New("If-Match does not match the current ETag")))
+	return 0, false, false
+}
+
+// notModified sets the ETag header for version and reports whether the
+// request's If-None-Match already names it, in which case it answers 304.
+func notModified(c *gin.Context, version uint) bool {
+	tag := etag(version)
+	c.Header("ETag", tag)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"backend/internal/health"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// HealthHandler answers the probes load balancers and orchestrators use to
+// decide whether an instance is alive and whether to route traffic to it.
+type HealthHandler struct {
+	checker *health.Checker
+	logger  *logrus.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *logrus.Logger) *HealthHandler {
+	return &HealthHandler{checker: checker, logger: logger}
+}
+
+// Live @Summary Liveness probe
+// @Description Reports that the process is up and serving HTTP. It checks no dependencies, so a failing database never gets the instance restarted.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Ready @Summary Readiness probe
+// @Description Runs the dependency checks and reports the status and latency of each. Answers 503 when a required check (database, schema) fails; optional checks (sms, auth) only downgrade the status to degraded. Why a check failed is only logged.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+	for name, result := range report.Checks {
+		if result.Status != health.StatusOK {
+			requestLog(c, h.logger).Warnf("Readiness check %s failed: %s", name, result.Error)
+		}
+	}
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
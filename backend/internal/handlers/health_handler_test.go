@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"backend/internal/health"
+	"backend/pkg/logging"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dbCheck := func(err error) health.Check {
+		return health.Check{Name: "database", Run: func(context.Context) error { return err }}
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		dbErr          error
+		expectedStatus int
+		expectedReport string
+	}{
+		{name: "Live", path: "/healthz", dbErr: errors.New("connection refused"), expectedStatus: http.StatusOK, expectedReport: health.StatusOK},
+		{name: "Ready", path: "/readyz", expectedStatus: http.StatusOK, expectedReport: health.StatusOK},
+		{name: "Not ready", path: "/readyz", dbErr: errors.New("connection refused"), expectedStatus: http.StatusServiceUnavailable, expectedReport: health.StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler(health.NewChecker(time.Second, dbCheck(tt.dbErr)), logging.GetLogger())
+			router := gin.New()
+			router.GET("/healthz", handler.Live)
+			router.GET("/readyz", handler.Ready)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var report struct {
+				Status string                            `json:"status"`
+				Checks map[string]map[string]interface{} `json:"checks"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, tt.expectedReport, report.Status)
+			assert.NotContains(t, w.Body.String(), "connection refused")
+			if tt.path == "/readyz" {
+				database := report.Checks["database"]
+				assert.Equal(t, tt.expectedReport, database["status"])
+				assert.Contains(t, database, "latency_ms")
+				assert.NotContains(t, database, "error")
+			}
+		})
+	}
+}
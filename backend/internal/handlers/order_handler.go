@@ -1,17 +1,38 @@
 package handlers
 
 import (
+	"backend/internal/apperrors"
 	"backend/internal/config"
 	"backend/internal/dto"
+	"backend/internal/metrics"
 	"backend/internal/models"
+	"backend/internal/problem"
+	"backend/internal/query"
 	"backend/internal/repositories"
 	"backend/internal/utils"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+// orderListSpec whitelists the order columns list endpoints may sort and filter on.
+var orderListSpec = query.Spec{
+	Sortable:    []string{"id", "created_at", "updated_at", "product_id", "quantity", "total", "status"},
+	DefaultSort: "id",
+	Filters: map[string]query.Filter{
+		"status":         {Column: "status", Parse: query.OneOf(models.OrderStatuses...), Multiple: true},
+		"product_id":     {Column: "product_id", Parse: query.Int, Multiple: true},
+		"user_id":        {Column: "user_id", Parse: query.Int, Multiple: true},
+		"created_after":  {Column: "created_at", Operator: query.AtLeast, Parse: query.Time},
+		"created_before": {Column: "created_at", Operator: query.AtMost, Parse: query.Time},
+	},
+}
+
 type OrderHandler struct {
 	repo   repositories.OrderRepositoryImpl
 	logger *logrus.Logger
@@ -27,102 +48,213 @@ func NewOrderHandler(repo repositories.OrderRepositoryImpl, logger *logrus.Logge
 // @Accept json
 // @Produce json
 // @Param order body dto.CreateOrderRequest true "Order"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request safe"
 // @Success 201 {object} dto.BaseResponse
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
-// @Failure 500 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 429 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/orders [post]
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
-	var createOrder dto.CreateOrderRequest
-	if err := c.ShouldBindJSON(&createOrder); err != nil {
-		h.logger.Warnf("invalid order data: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{Message: "Invalid order data", StatusCode: http.StatusBadRequest})
+	createOrder, ok := bindJSON[dto.CreateOrderRequest](c, h.logger, "create order")
+	if !ok {
 		return
 	}
 	order := models.Order{
 		ProductID: createOrder.ProductID,
 		Quantity:  createOrder.Quantity,
 		UserId:    createOrder.UserId,
+		Total:     createOrder.Total,
+		Status:    orderStatus(createOrder.Status),
 	}
 
-	if err := h.repo.Create(&order); err != nil {
-		h.logger.Warnf("failed to create order: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{Message: "Failed to create order", StatusCode: http.StatusInternalServerError})
+	if err := h.repo.Create(c.Request.Context(), &order); err != nil {
+		requestLog(c, h.logger).Warnf("failed to create order: %v", err)
+		_ = c.Error(err)
 		return
 	}
-	err := utils.SendSMS(config.AppConfig.SMSSandboxAPIKey, config.AppConfig.SMSSandboxUserName, "+254722123123", "Order created successfully")
+	metrics.OrdersCreated.Inc()
+	err := utils.SendSMS(c.Request.Context(), config.AppConfig.SMSSandboxAPIKey, config.AppConfig.SMSSandboxUserName, "+254722123123", "Order created successfully")
 	if err != nil {
-		h.logger.Warnf("failed to create order: %v", err)
+		requestLog(c, h.logger).Warnf("failed to create order: %v", err)
 	}
 	c.JSON(http.StatusCreated, dto.BaseResponse{Data: order, Message: "Order created successfully", StatusCode: http.StatusCreated})
 }
 
-// UpdateOrder @Summary Update an existing order
-// @Description Update an existing order
+// UpdateOrder @Summary Replace an existing order
+// @Description Replace every field of an existing order; use PATCH to change only some. If-Match must carry the ETag the order was read with.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
-// @Param order body dto.CreateOrderRequest true "Order"
+// @Param If-Match header string true "ETag of the order being replaced, or * for any version"
+// @Param order body dto.ReplaceOrderRequest true "Order"
 // @Success 200 {object} dto.BaseResponse
+// @Header 200 {string} ETag "Version of the updated order"
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
-// @Failure 500 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 428 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/orders/{id} [put]
 func (h *OrderHandler) UpdateOrder(c *gin.Context) {
-	var createOrder dto.CreateOrderRequest
+	id, ok := pathID(c, h.logger, "order")
+	if !ok {
+		return
+	}
 
-	id, err := strconv.Atoi(c.Param("id"))
+	version, wildcard, ok := ifMatch(c, "update order")
+	if !ok {
+		return
+	}
+
+	replacement, ok := bindJSON[dto.ReplaceOrderRequest](c, h.logger, "update order")
+	if !ok {
+		return
+	}
+
+	order, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		h.logger.Warnf("invalid order ID: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{Message: "Invalid order ID", StatusCode: http.StatusBadRequest})
+		requestLog(c, h.logger).Warnf("failed to get order by ID: %v", err)
+		_ = c.Error(err)
 		return
 	}
+	if !wildcard {
+		order.Version = version
+	}
+
+	order.ProductID = replacement.ProductID
+	order.Quantity = replacement.Quantity
+	order.UserId = replacement.UserId
+	order.Total = replacement.Total
+	order.Status = replacement.Status
 
-	if err := c.ShouldBindJSON(&createOrder); err != nil {
-		h.logger.Warnf("invalid order data: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{Message: "Invalid order data", StatusCode: http.StatusBadRequest})
+	if err := h.repo.Update(c.Request.Context(), order); err != nil {
+		requestLog(c, h.logger).Warnf("failed to update order: %v", err)
+		_ = c.Error(err)
 		return
 	}
 
-	order := models.Order{
-		ProductID: createOrder.ProductID,
-		Quantity:  createOrder.Quantity,
-		UserId:    createOrder.UserId,
+	c.Header("ETag", etag(order.Version))
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: *order, Message: "Order updated successfully", StatusCode: http.StatusOK})
+}
+
+// PatchOrder @Summary Partially update an order
+// @Description Change only the fields present in an RFC 7396 merge patch (application/merge-patch+json)
+// @Description or an RFC 6902 JSON Patch (application/json-patch+json). If-Match must carry the ETag the order was read with.
+// @Tags Orders
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param If-Match header string true "ETag of the order being patched, or * for any version"
+// @Param patch body dto.OrderPatch true "Fields to change"
+// @Success 200 {object} dto.BaseResponse
+// @Header 200 {string} ETag "Version of the patched order"
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 415 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 428 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/orders/{id} [patch]
+func (h *OrderHandler) PatchOrder(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "order")
+	if !ok {
+		return
 	}
 
-	order.ID = uint(id)
-	if err := h.repo.Update(&order); err != nil {
-		h.logger.Warnf("failed to update order: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{Message: "Failed to update order", StatusCode: http.StatusInternalServerError})
+	version, wildcard, ok := ifMatch(c, "patch order")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.BaseResponse{Data: order, Message: "Order updated successfully", StatusCode: http.StatusOK})
+	order, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to get order by ID: %v", err)
+		_ = c.Error(err)
+		return
+	}
+	if !wildcard && version != order.Version {
+		_ = c.Error(apperrors.PreconditionFailed("patch order", errors.New("order was modified by another request")))
+		return
+	}
+
+	patch, changed, ok := applyPatch(c, "patch order", dto.OrderPatch{
+		ProductID: order.ProductID,
+		Quantity:  order.Quantity,
+		UserId:    order.UserId,
+		Total:     order.Total,
+		Status:    order.Status,
+	})
+	if !ok {
+		return
+	}
+
+	if len(changed) > 0 {
+		order.ProductID = patch.ProductID
+		order.Quantity = patch.Quantity
+		order.UserId = patch.UserId
+		order.Total = patch.Total
+		order.Status = patch.Status
+		if err := h.repo.Patch(c.Request.Context(), order, changed); err != nil {
+			requestLog(c, h.logger).Warnf("failed to patch order: %v", err)
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	c.Header("ETag", etag(order.Version))
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: *order, Message: "Order updated successfully", StatusCode: http.StatusOK})
 }
 
 // DeleteOrder @Summary Delete an order
-// @Description Delete an existing order
+// @Description Delete an existing order. If-Match must carry the ETag the order was read with.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
+// @Param If-Match header string true "ETag of the order being deleted, or * for any version"
 // @Success 204 {object} dto.BaseResponse
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
-// @Failure 500 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 428 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/orders/{id} [delete]
 func (h *OrderHandler) DeleteOrder(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		h.logger.Warnf("invalid order ID: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{Message: "Invalid order ID", StatusCode: http.StatusBadRequest})
+	id, ok := pathID(c, h.logger, "order")
+	if !ok {
 		return
 	}
 
-	if err := h.repo.Delete(id); err != nil {
-		h.logger.Warnf("failed to delete order: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{Message: "Failed to delete order", StatusCode: http.StatusInternalServerError})
+	version, wildcard, ok := ifMatch(c, "delete order")
+	if !ok {
+		return
+	}
+
+	var err error
+	if wildcard {
+		err = h.repo.Delete(c.Request.Context(), id)
+	} else {
+		err = h.repo.DeleteIfVersion(c.Request.Context(), id, version)
+	}
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to delete order: %v", err)
+		_ = c.Error(err)
 		return
 	}
 
@@ -130,80 +262,298 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 }
 
 // GetOrderByID @Summary Get an order by ID
-// @Description Get an order by ID
+// @Description Get an order by ID. The ETag response header identifies its version; send it back
+// @Description in If-None-Match to get 304 while it is unchanged, or in If-Match to update or delete it.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} dto.BaseResponse
+// @Success 304 "Not Modified"
+// @Header 200,304 {string} ETag "Version of the order"
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
-// @Failure 500 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/orders/{id} [get]
 func (h *OrderHandler) GetOrderByID(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		h.logger.Warnf("invalid order ID: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{Message: "Invalid order ID", StatusCode: http.StatusBadRequest})
+	id, ok := pathID(c, h.logger, "order")
+	if !ok {
 		return
 	}
 
-	order, err := h.repo.GetByID(id)
+	order, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		h.logger.Warnf("failed to get order by ID: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{Message: "Failed to get order", StatusCode: http.StatusInternalServerError})
+		requestLog(c, h.logger).Warnf("failed to get order by ID: %v", err)
+		_ = c.Error(err)
 		return
 	}
 
+	if notModified(c, order.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, dto.BaseResponse{Data: *order, Message: "Order fetched successfully", StatusCode: http.StatusOK})
 }
 
 // GetAllOrders @Summary Get all orders
-// @Description Get all orders
+// @Description Get a page of orders, optionally filtered and sorted
 // @Tags Orders
 // @Accept json
 // @Produce json
+// @Param cursor query string false "Keyset cursor from a previous meta.next_cursor or meta.prev_cursor; send it empty to start keyset pagination"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (e.g. -created_at)"
+// @Param status query string false "Filter by status; comma separated for several"
+// @Param product_id query int false "Filter by product ID"
+// @Param user_id query int false "Filter by user ID"
+// @Param created_after query string false "Only orders created at or after this RFC 3339 time or date"
+// @Param created_before query string false "Only orders created at or before this RFC 3339 time or date"
 // @Success 200 {object} dto.BaseResponse
 // @Security ApiKeyAuth
-// @Failure 500 {object} dto.BaseResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/orders [get]
 func (h *OrderHandler) GetAllOrders(c *gin.Context) {
-	orders, err := h.repo.GetAll()
+	if query.UsesCursor(c.Request.URL.Query()) {
+		h.listOrdersByCursor(c, h.repo.List)
+		return
+	}
+
+	q, page, err := query.Parse(c.Request.URL.Query(), orderListSpec)
 	if err != nil {
-		h.logger.Warnf("failed to get all orders: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{Message: "Failed to get orders", StatusCode: http.StatusInternalServerError})
+		requestLog(c, h.logger).Warnf("invalid order query: %v", err)
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.BaseResponse{Data: orders, Message: "Orders fetched successfully", StatusCode: http.StatusOK})
+	orders, err := h.repo.List(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to get all orders: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	total, err := h.repo.Count(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to count orders: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: orders, Message: "Orders fetched successfully", StatusCode: http.StatusOK, Meta: dto.NewPagination(page.Number, page.Limit, total)})
 }
 
 // GetOrdersByUserID
 // @Summary Get orders by user ID
-// @Description Get orders by user ID
+// @Description Get a page of a user's orders, optionally filtered and sorted
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param user_id path int true "User ID"
+// @Param cursor query string false "Keyset cursor from a previous meta.next_cursor or meta.prev_cursor; send it empty to start keyset pagination"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (e.g. -created_at)"
+// @Param status query string false "Filter by status; comma separated for several"
+// @Param product_id query int false "Filter by product ID"
+// @Param created_after query string false "Only orders created at or after this RFC 3339 time or date"
+// @Param created_before query string false "Only orders created at or before this RFC 3339 time or date"
 // @Success 200 {object} dto.BaseResponse
 // @Security ApiKeyAuth
-// @Failure 400 {object} dto.BaseResponse
-// @Failure 500 {object} dto.BaseResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
 // @Router /api/v1/users/{user_id}/orders [get]
 func (h *OrderHandler) GetOrdersByUserID(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("user_id"))
 	if err != nil {
-		h.logger.Warnf("invalid user ID: %v", err)
-		c.JSON(http.StatusBadRequest, dto.BaseResponse{Message: "Invalid user ID", StatusCode: http.StatusBadRequest})
+		requestLog(c, h.logger).Warnf("invalid user ID: %v", err)
+		problem.Abort(c, problem.BadRequest, fmt.Sprintf("Invalid user ID: %s", c.Param("user_id")))
+		return
+	}
+
+	if query.UsesCursor(c.Request.URL.Query()) {
+		h.listOrdersByCursor(c, func(ctx context.Context, q repositories.Query) ([]models.Order, error) {
+			return h.repo.GetOrdersByUserID(ctx, userID, q)
+		})
+		return
+	}
+
+	q, page, err := query.Parse(c.Request.URL.Query(), orderListSpec)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("invalid order query: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	orders, err := h.repo.GetOrdersByUserID(c.Request.Context(), userID, q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to get orders by user ID: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	total, err := h.repo.CountOrdersByUserID(c.Request.Context(), userID, q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to count orders by user ID: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: orders, Message: "Orders fetched successfully", StatusCode: http.StatusOK, Meta: dto.NewPagination(page.Number, page.Limit, total)})
+}
+
+// listOrdersByCursor serves one keyset page of the orders returned by list.
+// Unlike offset pages, keyset pages stay stable while new orders arrive.
+func (h *OrderHandler) listOrdersByCursor(c *gin.Context, list func(ctx context.Context, q repositories.Query) ([]models.Order, error)) {
+	secret := config.AppConfig.CursorSecret
+	q, keyset, err := query.ParseKeyset(c.Request.URL.Query(), orderListSpec, secret)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("invalid order query: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	orders, err := list(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to get orders: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	orders, next, prev := query.Paginate(orders, keyset, orderKey, secret)
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Data:       orders,
+		Message:    "Orders fetched successfully",
+		StatusCode: http.StatusOK,
+		Meta:       dto.CursorPagination{Limit: keyset.Limit, NextCursor: next, PrevCursor: prev},
+	})
+}
+
+func orderKey(order models.Order) (time.Time, uint) {
+	return order.CreatedAt, order.ID
+}
+
+// orderStatus defaults an omitted status to pending.
+func orderStatus(status string) string {
+	if status == "" {
+		return models.OrderStatusPending
+	}
+	return status
+}
+
+// GetTrashedOrders @Summary List deleted orders
+// @Description Get a page of soft-deleted orders, most recently deleted first
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (default -deleted_at)"
+// @Param status query string false "Filter by status; comma separated for several"
+// @Param product_id query int false "Filter by product ID"
+// @Param user_id query int false "Filter by user ID"
+// @Param deleted_after query string false "Only orders deleted at or after this RFC 3339 time or date"
+// @Param deleted_before query string false "Only orders deleted at or before this RFC 3339 time or date"
+// @Success 200 {object} dto.BaseResponse
+// @Security ApiKeyAuth
+// @Failure 401 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/orders/trash [get]
+func (h *OrderHandler) GetTrashedOrders(c *gin.Context) {
+	q, page, err := query.Parse(c.Request.URL.Query(), orderListSpec.Trash())
+	if err != nil {
+		requestLog(c, h.logger).Warnf("invalid order query: %v", err)
+		_ = c.Error(err)
 		return
 	}
+	q = q.Where(repositories.Trashed)
 
-	orders, err := h.repo.GetOrdersByUserID(userID)
+	orders, err := h.repo.List(c.Request.Context(), q)
 	if err != nil {
-		h.logger.Warnf("failed to get orders by user ID: %v", err)
-		c.JSON(http.StatusInternalServerError, dto.BaseResponse{Message: "Failed to get orders", StatusCode: http.StatusInternalServerError})
+		requestLog(c, h.logger).Warnf("failed to get deleted orders: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	total, err := h.repo.Count(c.Request.Context(), q)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to count deleted orders: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: orders, Message: "Deleted orders fetched successfully", StatusCode: http.StatusOK, Meta: dto.NewPagination(page.Number, page.Limit, total)})
+}
+
+// RestoreOrder @Summary Restore a deleted order
+// @Description Move a soft-deleted order out of the trash
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} dto.BaseResponse
+// @Header 200 {string} ETag "Version of the restored order"
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/orders/{id}/restore [post]
+func (h *OrderHandler) RestoreOrder(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "order")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Restore(c.Request.Context(), id); err != nil {
+		requestLog(c, h.logger).Warnf("failed to restore order: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	order, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		requestLog(c, h.logger).Warnf("failed to get order by ID: %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", etag(order.Version))
+	c.JSON(http.StatusOK, dto.BaseResponse{Data: *order, Message: "Order restored successfully", StatusCode: http.StatusOK})
+}
+
+// PurgeOrder @Summary Permanently delete an order
+// @Description Permanently delete an order that is already in the trash. Administrators only.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 204 {object} dto.BaseResponse
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Router /api/v1/orders/trash/{id} [delete]
+func (h *OrderHandler) PurgeOrder(c *gin.Context) {
+	id, ok := pathID(c, h.logger, "order")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Purge(c.Request.Context(), id); err != nil {
+		requestLog(c, h.logger).Warnf("failed to purge order: %v", err)
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.BaseResponse{Data: orders, Message: "Orders fetched successfully", StatusCode: http.StatusOK})
+	c.JSON(http.StatusNoContent, dto.BaseResponse{Message: "Order purged successfully", StatusCode: http.StatusNoContent})
 }
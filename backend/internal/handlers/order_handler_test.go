@@ -1,13 +1,20 @@
 package handlers_test
 
 import (
+	"backend/internal/apperrors"
+	"backend/internal/config"
 	"backend/internal/dto"
 	"backend/internal/handlers"
+	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/problem"
+	"backend/internal/repositories"
 	"backend/mocks"
 	"backend/pkg/logging"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -29,7 +36,7 @@ func TestOrderHandler_CreateOrder(t *testing.T) {
 	router.POST("/api/v1/orders", handler.CreateOrder)
 
 	order := dto.CreateOrderRequest{ProductID: 1, Quantity: 2, UserId: 1}
-	mockRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 
 	reqBody, _ := json.Marshal(order)
 	req, _ := http.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(reqBody))
@@ -47,6 +54,75 @@ func TestOrderHandler_CreateOrder(t *testing.T) {
 	assert.Equal(t, float64(order.ProductID), response.Data.(map[string]interface{})["product_id"])
 }
 
+func TestOrderHandler_CreateOrder_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedErrors []dto.FieldError
+	}{
+		{
+			name:           "Rule violations",
+			body:           `{"product_id":0,"quantity":-1,"user_id":1,"total":10.005}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErrors: []dto.FieldError{
+				{Field: "product_id", Code: "required", Message: "product_id is required"},
+				{Field: "quantity", Code: "gt", Message: "quantity must be greater than 0"},
+				{Field: "total", Code: "positive_money", Message: "total must be an amount above zero with at most 2 decimal places"},
+			},
+		},
+		{
+			name:           "Wrong type",
+			body:           `{"product_id":"one","quantity":1,"user_id":1}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErrors: []dto.FieldError{
+				{Field: "product_id", Code: "type", Message: "product_id must be a number"},
+			},
+		},
+		{
+			name:           "Unknown status",
+			body:           `{"product_id":1,"quantity":1,"user_id":1,"status":"lost"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErrors: []dto.FieldError{
+				{Field: "status", Code: "oneof", Message: "status must be one of: pending, confirmed, delivered, cancelled"},
+			},
+		},
+		{
+			name:           "Malformed JSON",
+			body:           `{"product_id":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := handlers.NewOrderHandler(mocks.NewMockOrderRepositoryImpl(ctrl), logger)
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.POST("/api/v1/orders", handler.CreateOrder)
+
+			req, _ := http.NewRequest("POST", "/api/v1/orders", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			var response dto.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			assert.Equal(t, tt.expectedErrors, response.Errors)
+		})
+	}
+}
+
 func TestOrderHandler_UpdateOrder(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctrl := gomock.NewController(t)
@@ -59,17 +135,29 @@ func TestOrderHandler_UpdateOrder(t *testing.T) {
 	router := gin.Default()
 	router.PUT("/api/v1/orders/:id", handler.UpdateOrder)
 
-	order := models.Order{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0}
-	mockRepo.EXPECT().Update(gomock.Any()).Return(nil)
+	order := models.Order{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0, Status: models.OrderStatusPending}
+	stored := models.Order{ProductID: 3, Quantity: 1, UserId: 1, Total: 35.5, Status: models.OrderStatusConfirmed}
+	stored.ID = 1
+	stored.Version = 3
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&stored, nil)
+	mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, o *models.Order) error {
+		assert.Equal(t, uint(2), o.Version)
+		assert.Equal(t, 20.0, o.Total)
+		assert.Equal(t, models.OrderStatusPending, o.Status)
+		o.Version++
+		return nil
+	})
 
 	reqBody, _ := json.Marshal(order)
 	req, _ := http.NewRequest("PUT", "/api/v1/orders/1", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
 
 	var response dto.BaseResponse
 	_ = json.Unmarshal(w.Body.Bytes(), &response)
@@ -78,6 +166,57 @@ func TestOrderHandler_UpdateOrder(t *testing.T) {
 	assert.Equal(t, float64(order.ProductID), response.Data.(map[string]interface{})["product_id"])
 }
 
+func TestOrderHandler_UpdateOrder_RequiresEveryField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedErrors []dto.FieldError
+	}{
+		{
+			name: "Missing total and status",
+			body: `{"product_id":1,"quantity":2,"user_id":1}`,
+			expectedErrors: []dto.FieldError{
+				{Field: "total", Code: "positive_money", Message: "total must be an amount above zero with at most 2 decimal places"},
+				{Field: "status", Code: "required", Message: "status is required"},
+			},
+		},
+		{
+			name: "Zero total",
+			body: `{"product_id":1,"quantity":2,"user_id":1,"total":0,"status":"pending"}`,
+			expectedErrors: []dto.FieldError{
+				{Field: "total", Code: "positive_money", Message: "total must be an amount above zero with at most 2 decimal places"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := handlers.NewOrderHandler(mocks.NewMockOrderRepositoryImpl(ctrl), logger)
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.PUT("/api/v1/orders/:id", handler.UpdateOrder)
+
+			req, _ := http.NewRequest("PUT", "/api/v1/orders/1", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", `"2"`)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+			var response dto.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedErrors, response.Errors)
+		})
+	}
+}
+
 func TestOrderHandler_DeleteOrder(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctrl := gomock.NewController(t)
@@ -90,9 +229,10 @@ func TestOrderHandler_DeleteOrder(t *testing.T) {
 	router := gin.Default()
 	router.DELETE("/api/v1/orders/:id", handler.DeleteOrder)
 
-	mockRepo.EXPECT().Delete(1).Return(nil)
+	mockRepo.EXPECT().DeleteIfVersion(gomock.Any(), 1, uint(4)).Return(nil)
 
 	req, _ := http.NewRequest("DELETE", "/api/v1/orders/1", nil)
+	req.Header.Set("If-Match", `"4"`)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -117,7 +257,8 @@ func TestOrderHandler_GetOrderByID(t *testing.T) {
 	router.GET("/api/v1/orders/:id", handler.GetOrderByID)
 
 	order := models.Order{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0}
-	mockRepo.EXPECT().GetByID(1).Return(&order, nil)
+	order.Version = 1
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&order, nil)
 
 	req, _ := http.NewRequest("GET", "/api/v1/orders/1", nil)
 
@@ -131,6 +272,116 @@ func TestOrderHandler_GetOrderByID(t *testing.T) {
 	assert.Equal(t, "Order fetched successfully", response.Message)
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 	assert.Equal(t, float64(order.ProductID), response.Data.(map[string]interface{})["product_id"])
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+}
+
+func TestOrderHandler_GetOrderByID_NotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	handler := handlers.NewOrderHandler(mockRepo, logging.GetLogger())
+
+	router := gin.New()
+	router.GET("/api/v1/orders/:id", handler.GetOrderByID)
+
+	order := models.Order{ProductID: 1, Quantity: 2, UserId: 1}
+	order.Version = 5
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&order, nil).Times(2)
+
+	for _, tt := range []struct {
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{`"4", W/"5"`, http.StatusNotModified},
+		{`"4"`, http.StatusOK},
+	} {
+		req, _ := http.NewRequest("GET", "/api/v1/orders/1", nil)
+		req.Header.Set("If-None-Match", tt.ifNoneMatch)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.expectedStatus, w.Code, tt.ifNoneMatch)
+		assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+		if tt.expectedStatus == http.StatusNotModified {
+			assert.Empty(t, w.Body.String())
+		}
+	}
+}
+
+func TestOrderHandler_Preconditions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+
+	tests := []struct {
+		name           string
+		method         string
+		ifMatch        string
+		setup          func(repo *mocks.MockOrderRepositoryImpl)
+		expectedStatus int
+	}{
+		{
+			name:           "Update without If-Match",
+			method:         "PUT",
+			setup:          func(repo *mocks.MockOrderRepositoryImpl) {},
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "Delete with a weak ETag",
+			method:         "DELETE",
+			ifMatch:        `W/"1"`,
+			setup:          func(repo *mocks.MockOrderRepositoryImpl) {},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Update of a stale version",
+			method:  "PUT",
+			ifMatch: `"1"`,
+			setup: func(repo *mocks.MockOrderRepositoryImpl) {
+				repo.EXPECT().GetByID(gomock.Any(), 1).Return(&models.Order{}, nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(apperrors.PreconditionFailed("update order", errors.New("order was modified by another request")))
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Delete of any version",
+			method:  "DELETE",
+			ifMatch: "*",
+			setup: func(repo *mocks.MockOrderRepositoryImpl) {
+				repo.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+			tt.setup(mockRepo)
+			handler := handlers.NewOrderHandler(mockRepo, logger)
+
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.PUT("/api/v1/orders/:id", handler.UpdateOrder)
+			router.DELETE("/api/v1/orders/:id", handler.DeleteOrder)
+
+			req, _ := http.NewRequest(tt.method, "/api/v1/orders/1", bytes.NewBufferString(`{"product_id":1,"quantity":2,"user_id":1,"total":20,"status":"pending"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
 }
 
 func TestOrderHandler_GetAllOrders(t *testing.T) {
@@ -149,9 +400,10 @@ func TestOrderHandler_GetAllOrders(t *testing.T) {
 		{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0},
 		{ProductID: 2, Quantity: 1, UserId: 1, Total: 10.0},
 	}
-	mockRepo.EXPECT().GetAll().Return(orders, nil)
+	mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(orders, nil)
+	mockRepo.EXPECT().Count(gomock.Any(), gomock.Any()).Return(int64(45), nil)
 
-	req, _ := http.NewRequest("GET", "/api/v1/orders", nil)
+	req, _ := http.NewRequest("GET", "/api/v1/orders?page=2&limit=20&sort=-created_at&status=pending,confirmed", nil)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -163,6 +415,30 @@ func TestOrderHandler_GetAllOrders(t *testing.T) {
 	assert.Equal(t, "Orders fetched successfully", response.Message)
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 	assert.Equal(t, float64(orders[0].ProductID), response.Data.([]interface{})[0].(map[string]interface{})["product_id"])
+	assert.Equal(t, map[string]interface{}{"page": float64(2), "limit": float64(20), "total": float64(45), "total_pages": float64(3)}, response.Meta)
+}
+
+func TestOrderHandler_GetAllOrders_InvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	logger := logging.GetLogger()
+	handler := handlers.NewOrderHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.GET("/api/v1/orders", handler.GetAllOrders)
+
+	for _, rawQuery := range []string{"sort=password", "status=lost", "limit=1000", "page=0", "created_after=yesterday"} {
+		req, _ := http.NewRequest("GET", "/api/v1/orders?"+rawQuery, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, rawQuery)
+	}
 }
 
 func TestOrderHandler_GetOrdersByUserID(t *testing.T) {
@@ -181,7 +457,8 @@ func TestOrderHandler_GetOrdersByUserID(t *testing.T) {
 		{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0},
 		{ProductID: 2, Quantity: 1, UserId: 1, Total: 10.0},
 	}
-	mockRepo.EXPECT().GetOrdersByUserID(1).Return(orders, nil)
+	mockRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1, gomock.Any()).Return(orders, nil)
+	mockRepo.EXPECT().CountOrdersByUserID(gomock.Any(), 1, gomock.Any()).Return(int64(len(orders)), nil)
 
 	req, _ := http.NewRequest("GET", "/api/v1/users/1/orders", nil)
 
@@ -196,3 +473,314 @@ func TestOrderHandler_GetOrdersByUserID(t *testing.T) {
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 	assert.Equal(t, float64(orders[0].ProductID), response.Data.([]interface{})[0].(map[string]interface{})["product_id"])
 }
+
+func TestOrderHandler_GetOrderByID_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	logger := logging.GetLogger()
+	handler := handlers.NewOrderHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.GET("/api/v1/orders/:id", handler.GetOrderByID)
+
+	mockRepo.EXPECT().GetByID(gomock.Any(), 42).Return(nil, apperrors.NotFound("get order by ID", errors.New("record not found")))
+
+	req, _ := http.NewRequest("GET", "/api/v1/orders/42", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusNotFound, response.Status)
+	assert.Equal(t, problem.TypeBase+"not-found", response.Type)
+	assert.Equal(t, "/api/v1/orders/42", response.Instance)
+}
+
+func TestOrderHandler_DeleteOrder_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	logger := logging.GetLogger()
+	handler := handlers.NewOrderHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.DELETE("/api/v1/orders/:id", handler.DeleteOrder)
+
+	mockRepo.EXPECT().Delete(gomock.Any(), 42).Return(apperrors.NotFound("delete order", errors.New("record not found")))
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/orders/42", nil)
+	req.Header.Set("If-Match", "*")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOrderHandler_GetAllOrders_Unavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	logger := logging.GetLogger()
+	handler := handlers.NewOrderHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.GET("/api/v1/orders", handler.GetAllOrders)
+
+	mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, apperrors.Unavailable("get all orders", errors.New("connection refused")))
+
+	req, _ := http.NewRequest("GET", "/api/v1/orders", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestOrderHandler_GetAllOrders_Cursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.AppConfig.CursorSecret = []byte("test-secret")
+	t.Cleanup(func() { config.AppConfig.CursorSecret = nil })
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	logger := logging.GetLogger()
+	handler := handlers.NewOrderHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.GET("/api/v1/orders", handler.GetAllOrders)
+
+	orders := []models.Order{
+		{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0},
+		{ProductID: 2, Quantity: 1, UserId: 1, Total: 10.0},
+		{ProductID: 3, Quantity: 1, UserId: 1, Total: 10.0},
+	}
+	for i := range orders {
+		orders[i].ID = uint(i + 1)
+	}
+	mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, q repositories.Query) ([]models.Order, error) {
+		assert.Equal(t, 3, q.Limit)
+		return orders, nil
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v1/orders?cursor=&limit=2", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.BaseResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response.Data, 2)
+	meta := response.Meta.(map[string]interface{})
+	assert.Equal(t, float64(2), meta["limit"])
+	assert.NotEmpty(t, meta["next_cursor"])
+	assert.Nil(t, meta["prev_cursor"])
+}
+
+func TestOrderHandler_PatchOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		ifMatch         string
+		expectedStatus  int
+		expectedColumns []string
+	}{
+		{
+			name:            "Merge patch changes only the given field",
+			contentType:     "application/merge-patch+json",
+			body:            `{"quantity":5}`,
+			expectedStatus:  http.StatusOK,
+			expectedColumns: []string{"quantity"},
+		},
+		{
+			name:            "Plain JSON is treated as a merge patch",
+			contentType:     "application/json",
+			body:            `{"status":"confirmed","quantity":2}`,
+			expectedStatus:  http.StatusOK,
+			expectedColumns: []string{"status"},
+		},
+		{
+			name:            "JSON Patch",
+			contentType:     "application/json-patch+json",
+			body:            `[{"op":"test","path":"/status","value":"pending"},{"op":"replace","path":"/quantity","value":4}]`,
+			expectedStatus:  http.StatusOK,
+			expectedColumns: []string{"quantity"},
+		},
+		{
+			name:           "Unchanged document writes nothing",
+			contentType:    "application/merge-patch+json",
+			body:           `{}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Failed JSON Patch test",
+			contentType:    "application/json-patch+json",
+			body:           `[{"op":"test","path":"/status","value":"delivered"}]`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Invalid value",
+			contentType:    "application/merge-patch+json",
+			body:           `{"quantity":0}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Removing a required field",
+			contentType:    "application/merge-patch+json",
+			body:           `{"status":null}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Unknown field",
+			contentType:    "application/merge-patch+json",
+			body:           `{"created_at":"2024-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:            "Total",
+			contentType:     "application/merge-patch+json",
+			body:            `{"total":12.5}`,
+			expectedStatus:  http.StatusOK,
+			expectedColumns: []string{"total"},
+		},
+		{
+			name:           "Negative total",
+			contentType:    "application/merge-patch+json",
+			body:           `{"total":-12.5}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Malformed patch",
+			contentType:    "application/merge-patch+json",
+			body:           `[1]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unsupported media type",
+			contentType:    "text/plain",
+			body:           `quantity=5`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "Stale version",
+			contentType:    "application/merge-patch+json",
+			body:           `{"quantity":5}`,
+			ifMatch:        `"1"`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			stored := models.Order{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0, Status: models.OrderStatusPending}
+			stored.ID = 1
+			stored.Version = 2
+
+			mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+			mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&stored, nil)
+			if tt.expectedColumns != nil {
+				mockRepo.EXPECT().Patch(gomock.Any(), gomock.Any(), tt.expectedColumns).DoAndReturn(func(_ context.Context, o *models.Order, _ []string) error {
+					o.Version++
+					return nil
+				})
+			}
+			handler := handlers.NewOrderHandler(mockRepo, logger)
+
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.PATCH("/api/v1/orders/:id", handler.PatchOrder)
+
+			ifMatch := tt.ifMatch
+			if ifMatch == "" {
+				ifMatch = `"2"`
+			}
+			req, _ := http.NewRequest("PATCH", "/api/v1/orders/1", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("If-Match", ifMatch)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedColumns != nil {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+				assert.Equal(t, 1, stored.UserId)
+			}
+		})
+	}
+}
+
+func TestOrderHandler_Trash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepositoryImpl(ctrl)
+	logger := logging.GetLogger()
+	handler := handlers.NewOrderHandler(mockRepo, logger)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(logger))
+	router.GET("/api/v1/orders/:id", handler.GetOrderByID)
+	router.DELETE("/api/v1/orders/:id", handler.DeleteOrder)
+	router.GET("/api/v1/orders/trash", handler.GetTrashedOrders)
+	router.DELETE("/api/v1/orders/trash/:id", handler.PurgeOrder)
+	router.POST("/api/v1/orders/:id/restore", handler.RestoreOrder)
+
+	restored := models.Order{ProductID: 1, Quantity: 2, UserId: 1}
+	restored.Version = 4
+	gomock.InOrder(
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]models.Order{{ProductID: 1}}, nil),
+		mockRepo.EXPECT().Count(gomock.Any(), gomock.Any()).Return(int64(1), nil),
+		mockRepo.EXPECT().Restore(gomock.Any(), 7).Return(nil),
+		mockRepo.EXPECT().GetByID(gomock.Any(), 7).Return(&restored, nil),
+		mockRepo.EXPECT().Restore(gomock.Any(), 8).Return(apperrors.NotFound("restore order", errors.New("record not found"))),
+		mockRepo.EXPECT().Purge(gomock.Any(), 7).Return(nil),
+	)
+
+	tests := []struct {
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"GET", "/api/v1/orders/trash?sort=-deleted_at&deleted_after=2024-06-14", http.StatusOK},
+		{"GET", "/api/v1/orders/trash?sort=password", http.StatusUnprocessableEntity},
+		{"POST", "/api/v1/orders/7/restore", http.StatusOK},
+		{"POST", "/api/v1/orders/8/restore", http.StatusNotFound},
+		{"DELETE", "/api/v1/orders/trash/7", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.expectedStatus, w.Code, tt.path)
+		if tt.path == "/api/v1/orders/7/restore" {
+			assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"backend/internal/dto"
+	"backend/internal/models"
+	"backend/internal/problem"
+	"backend/internal/repositories"
+	"backend/pkg/authentication"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// orderStreamBatch bounds the events read from the audit log per poll.
+	orderStreamBatch = 100
+	// orderStreamRetry is how long browsers wait before reconnecting.
+	orderStreamRetry = 3 * time.Second
+)
+
+var byIDAscending = []clause.OrderByColumn{{Column: clause.Column{Name: "id"}}}
+
+// OrderStreamHandler pushes order changes to clients as Server-Sent Events.
+// The audit log is the event log: every order change is recorded there in
+// the same transaction, so a client that reconnects with Last-Event-ID misses
+// nothing that happened in between, whichever instance it reaches.
+//
+// Audit IDs are assigned when an entry is written but become visible when its
+// transaction commits, so a lower ID can show up after a higher one was read,
+// for instance behind a batch. A stream therefore re-reads the entries of the
+// last grace period on every poll and skips those it already sent. Only
+// entries older than grace are taken as settled; the highest of them is the
+// position sent as event ID, from which a reconnecting client resumes.
+type OrderStreamHandler struct {
+	repo         repositories.AuditRepositoryImpl
+	done         <-chan struct{}
+	pollInterval time.Duration
+	heartbeat    time.Duration
+	grace        time.Duration
+	logger       *logrus.Logger
+}
+
+// NewOrderStreamHandler returns a handler whose streams end when ctx is done,
+// so they do not hold up a graceful shutdown. grace bounds how long a
+// transaction writing an order change may stay open.
+func NewOrderStreamHandler(ctx context.Context, repo repositories.AuditRepositoryImpl, pollInterval, heartbeat, grace time.Duration, logger *logrus.Logger) *OrderStreamHandler {
+	return &OrderStreamHandler{repo: repo, done: ctx.Done(), pollInterval: pollInterval, heartbeat: heartbeat, grace: grace, logger: logger}
+}
+
+// streamCursor tracks what a stream has sent. Entries up to floor are
+// settled; sent holds the entries above it and when they were first read.
+type streamCursor struct {
+	floor uint
+	sent  map[uint]time.Time
+}
+
+// settle moves the floor past the entries read at least grace ago and forgets
+// them.
+func (s *streamCursor) settle(now time.Time, grace time.Duration) {
+	for id, readAt := range s.sent {
+		if now.Sub(readAt) >= grace && id > s.floor {
+			s.floor = id
+		}
+	}
+	for id := range s.sent {
+		if id <= s.floor {
+			delete(s.sent, id)
+		}
+	}
+}
+
+// StreamOrders @Summary Stream order changes
+// @Description Server-Sent Events stream of order changes: order.created, order.updated, order.status_changed, order.deleted, order.restored and order.purged. Each event's data is an OrderEvent; its id is a resume position, not the audit entry ID. Reconnect with Last-Event-ID (or last_event_id) to receive what was missed; events sent shortly before the disconnect may be repeated, so skip data.id values already seen. Without it the stream starts with the next change. Comment lines are sent as heartbeats. Administrators receive every order, optionally narrowed with user_id; other users only their own orders.
+// @Tags Orders
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "Event ID of the last event received"
+// @Param last_event_id query int false "Event ID of the last event received, for clients that cannot set headers"
+// @Param user_id query int false "Only orders of this user (administrators)"
+// @Success 200 {object} dto.OrderEvent
+// @Security ApiKeyAuth
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Router /api/v1/orders/stream [get]
+func (h *OrderStreamHandler) StreamOrders(c *gin.Context) {
+	ctx := c.Request.Context()
+	query, ok := h.streamQuery(c)
+	if !ok {
+		return
+	}
+	floor, resuming, ok := lastEventID(c)
+	if !ok {
+		return
+	}
+	cursor := &streamCursor{floor: floor, sent: make(map[uint]time.Time)}
+	if !resuming {
+		// Start from the settled entries and take the unsettled ones as sent,
+		// so the stream begins with the next change without losing one that
+		// commits behind them.
+		settled, err := h.repo.List(ctx, repositories.Query{
+			Scopes: []repositories.Scope{repositories.OfEntity("order"), repositories.RecordedBefore(time.Now().Add(-h.grace))},
+			Sort:   []clause.OrderByColumn{{Column: clause.Column{Name: "id"}, Desc: true}},
+			Limit:  1,
+		})
+		if err == nil && len(settled) > 0 {
+			cursor.floor = settled[0].ID
+		}
+		if err == nil {
+			err = h.read(ctx, query, cursor, func(models.AuditLog) error { return nil })
+		}
+		if err != nil {
+			requestLog(c, h.logger).Warnf("failed to start order stream: %v", err)
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	// Streams outlive the server's write timeout; lift it for this response.
+	controller := http.NewResponseController(c.Writer)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		requestLog(c, h.logger).Warnf("failed to lift write deadline of order stream: %v", err)
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(format string, args ...interface{}) bool {
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+	if !send("retry: %d\n\n", orderStreamRetry.Milliseconds()) {
+		return
+	}
+
+	errClosed := errors.New("order stream closed")
+	publish := func() bool {
+		floor := cursor.floor
+		err := h.read(ctx, query, cursor, func(entry models.AuditLog) error {
+			event := dto.NewOrderEvent(entry)
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if !send("id: %d\nevent: %s\ndata: %s\n\n", floor, event.Type, data) {
+				return errClosed
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, errClosed):
+			return false
+		case err != nil && ctx.Err() == nil:
+			requestLog(c, h.logger).Warnf("failed to read order events: %v", err)
+		}
+		cursor.settle(time.Now(), h.grace)
+		return true
+	}
+
+	poll := time.NewTicker(h.pollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	if !publish() {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			return
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		case <-poll.C:
+			if !publish() {
+				return
+			}
+		}
+	}
+}
+
+// read hands every entry above the cursor's floor that was not sent yet to
+// emit, in ID order, and records it as sent.
+func (h *OrderStreamHandler) read(ctx context.Context, query repositories.Query, cursor *streamCursor, emit func(models.AuditLog) error) error {
+	after := cursor.floor
+	for {
+		entries, err := h.repo.List(ctx, query.Where(repositories.AfterID(after)))
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, entry := range entries {
+			after = entry.ID
+			if _, ok := cursor.sent[entry.ID]; ok {
+				continue
+			}
+			if err := emit(entry); err != nil {
+				return err
+			}
+			cursor.sent[entry.ID] = now
+		}
+		// A full batch means more entries are waiting; read them right away.
+		if len(entries) < orderStreamBatch {
+			return nil
+		}
+	}
+}
+
+// streamQuery selects the order events the caller may see.
+func (h *OrderStreamHandler) streamQuery(c *gin.Context) (repositories.Query, bool) {
+	query := repositories.Query{
+		Scopes: []repositories.Scope{repositories.OfEntity("order")},
+		Sort:   byIDAscending,
+		Limit:  orderStreamBatch,
+	}
+
+	principal := authentication.PrincipalFrom(c.Request.Context())
+	if principal != nil && principal.Admin {
+		if raw := c.Query("user_id"); raw != "" {
+			userID, err := strconv.Atoi(raw)
+			if err != nil {
+				problem.Abort(c, problem.BadRequest, "Invalid user ID: "+raw)
+				return query, false
+			}
+			query = query.Where(repositories.ForOrdersOf(userID))
+		}
+		return query, true
+	}
+
+	var userID int
+	var err error
+	if principal != nil {
+		userID, err = strconv.Atoi(principal.UserID)
+	}
+	if principal == nil || err != nil {
+		problem.Abort(c, problem.Forbidden, "Order updates can only be streamed for your own orders")
+		return query, false
+	}
+	if raw := c.Query("user_id"); raw != "" && raw != principal.UserID {
+		problem.Abort(c, problem.Forbidden, "Only administrators can stream the orders of other users")
+		return query, false
+	}
+	return query.Where(repositories.ForOrdersOf(userID)), true
+}
+
+// lastEventID reads the ID of the last event a reconnecting client received
+// from the Last-Event-ID header or the last_event_id query parameter.
+func lastEventID(c *gin.Context) (uint, bool, bool) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, false, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest, "Invalid Last-Event-ID: "+raw)
+		return 0, false, false
+	}
+	return uint(id), true, true
+}
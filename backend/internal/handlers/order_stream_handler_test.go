@@ -0,0 +1,221 @@
+package handlers_test
+
+import (
+	"backend/internal/dto"
+	"backend/internal/handlers"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/mocks"
+	"backend/pkg/authentication"
+	"backend/pkg/logging"
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrderStreamHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+
+	statusChange := models.AuditLog{ID: 5, Entity: "order", EntityID: 3, Action: models.AuditUpdate, Actor: "wanjiru",
+		Changes: map[string]models.FieldChange{"status": {From: json.RawMessage(`"pending"`), To: json.RawMessage(`"shipped"`)}}}
+
+	tests := []struct {
+		name           string
+		principal      *authentication.Principal
+		path           string
+		lastEventID    string
+		heartbeat      time.Duration
+		latest         []models.AuditLog
+		events         []models.AuditLog
+		expectedStatus int
+		expectedScopes int
+		expectedBody   []string
+	}{
+		{
+			name:           "Resume from Last-Event-ID",
+			principal:      &authentication.Principal{UserID: "7"},
+			path:           "/api/v1/orders/stream",
+			lastEventID:    "4",
+			events:         []models.AuditLog{statusChange},
+			expectedStatus: http.StatusOK,
+			expectedScopes: 3,
+			expectedBody:   []string{"retry: 3000\n\n", "id: 4\nevent: order.status_changed\ndata: {\"id\":5,\"type\":\"order.status_changed\",\"order_id\":3,"},
+		},
+		{
+			name:           "Admin sees every order",
+			principal:      &authentication.Principal{UserID: "1", Admin: true},
+			path:           "/api/v1/orders/stream?last_event_id=4",
+			events:         []models.AuditLog{{ID: 6, Entity: "order", EntityID: 9, Action: models.AuditDelete}},
+			expectedStatus: http.StatusOK,
+			expectedScopes: 2,
+			expectedBody:   []string{"id: 4\nevent: order.deleted\ndata: {\"id\":6,"},
+		},
+		{
+			name:           "Starts after the latest event",
+			principal:      &authentication.Principal{UserID: "1", Admin: true},
+			path:           "/api/v1/orders/stream?user_id=7",
+			latest:         []models.AuditLog{{ID: 4}},
+			events:         []models.AuditLog{statusChange},
+			expectedStatus: http.StatusOK,
+			expectedScopes: 3,
+			expectedBody:   []string{"id: 4\nevent: order.status_changed\ndata: {\"id\":5,"},
+		},
+		{
+			name:           "Heartbeat",
+			principal:      &authentication.Principal{UserID: "7"},
+			path:           "/api/v1/orders/stream",
+			lastEventID:    "4",
+			heartbeat:      time.Millisecond,
+			expectedStatus: http.StatusOK,
+			expectedScopes: 3,
+			expectedBody:   []string{": heartbeat\n\n"},
+		},
+		{name: "Other user's orders", principal: &authentication.Principal{UserID: "7"}, path: "/api/v1/orders/stream?user_id=8", expectedStatus: http.StatusForbidden},
+		{name: "Non-numeric user", principal: &authentication.Principal{UserID: "octocat"}, path: "/api/v1/orders/stream", expectedStatus: http.StatusForbidden},
+		{name: "Invalid Last-Event-ID", principal: &authentication.Principal{UserID: "7"}, path: "/api/v1/orders/stream", lastEventID: "abc", expectedStatus: http.StatusBadRequest},
+		{name: "Invalid user filter", principal: &authentication.Principal{UserID: "1", Admin: true}, path: "/api/v1/orders/stream?user_id=abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockAuditRepositoryImpl(ctrl)
+			if tt.expectedStatus == http.StatusOK {
+				if tt.latest != nil {
+					mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q repositories.Query) ([]models.AuditLog, error) {
+						assert.Equal(t, 1, q.Limit)
+						return tt.latest, nil
+					})
+					// Nothing was written within the grace period.
+					mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+				}
+				first := mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q repositories.Query) ([]models.AuditLog, error) {
+					assert.Len(t, q.Scopes, tt.expectedScopes)
+					return tt.events, nil
+				})
+				mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil).After(first).AnyTimes()
+			}
+
+			heartbeat := tt.heartbeat
+			if heartbeat == 0 {
+				heartbeat = time.Hour
+			}
+			handler := handlers.NewOrderStreamHandler(context.Background(), mockRepo, 5*time.Millisecond, heartbeat, time.Minute, logger)
+
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(logger))
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(authentication.WithPrincipal(c.Request.Context(), tt.principal))
+			})
+			router.GET("/api/v1/orders/stream", handler.StreamOrders)
+
+			// The stream runs until the client goes away.
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, "GET", tt.path, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+				for _, expected := range tt.expectedBody {
+					assert.Contains(t, w.Body.String(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderStreamHandler_EndsOnShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuditRepositoryImpl(ctrl)
+	mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	shutdown, endStreams := context.WithCancel(context.Background())
+	handler := handlers.NewOrderStreamHandler(shutdown, mockRepo, time.Millisecond, time.Hour, time.Minute, logger)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(authentication.WithPrincipal(c.Request.Context(), &authentication.Principal{UserID: "1", Admin: true}))
+	})
+	router.GET("/api/v1/orders/stream", handler.StreamOrders)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req, _ := http.NewRequest("GET", "/api/v1/orders/stream?last_event_id=0", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	endStreams()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end on shutdown")
+	}
+}
+
+func TestOrderStreamHandler_LateCommits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.GetLogger()
+	db := openBatchDB(t)
+
+	handler := handlers.NewOrderStreamHandler(context.Background(), repositories.NewAuditRepository(db, logger), 5*time.Millisecond, time.Hour, time.Minute, logger)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(authentication.WithPrincipal(c.Request.Context(), &authentication.Principal{UserID: "1", Admin: true}))
+	})
+	router.GET("/api/v1/orders/stream", handler.StreamOrders)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/v1/orders/stream?last_event_id=0", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() dto.OrderEvent {
+		for lines.Scan() {
+			if data, ok := strings.CutPrefix(lines.Text(), "data: "); ok {
+				var event dto.OrderEvent
+				require.NoError(t, json.Unmarshal([]byte(data), &event))
+				return event
+			}
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return dto.OrderEvent{}
+	}
+
+	// Two transactions took IDs 6 and 7, and the one holding 7 commits first.
+	require.NoError(t, db.Create(&models.AuditLog{ID: 7, Entity: "order", EntityID: 2, Action: models.AuditCreate, Actor: "wanjiru"}).Error)
+	assert.Equal(t, uint(7), next().ID)
+	require.NoError(t, db.Create(&models.AuditLog{ID: 6, Entity: "order", EntityID: 1, Action: models.AuditCreate, Actor: "wanjiru"}).Error)
+	assert.Equal(t, uint(6), next().ID)
+
+	// Neither is sent twice.
+	require.NoError(t, db.Create(&models.AuditLog{ID: 8, Entity: "order", EntityID: 3, Action: models.AuditCreate, Actor: "wanjiru"}).Error)
+	assert.Equal(t, uint(8), next().ID)
+}
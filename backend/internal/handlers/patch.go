@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/problem"
+	"backend/internal/validation"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// applyPatch applies the request body of c to doc, which holds the current
+// values of the fields a client may patch. The body is an RFC 7396 merge patch
+// (application/merge-patch+json or plain application/json) or an RFC 6902
+// JSON Patch (application/json-patch+json). It returns the patched document
+// and the JSON names of the fields whose value changed, after validating just
+// those fields. When ok is false a response has already been produced.
+func applyPatch[D any](c *gin.Context, op string, doc D) (patched D, changed []string, ok bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		badPatch(c, err)
+		return patched, nil, false
+	}
+	original, err := json.Marshal(doc)
+	if err != nil {
+		_ = c.Error(err)
+		return patched, nil, false
+	}
+
+	var result []byte
+	switch c.ContentType() {
+	case mergePatchContentType, binding.MIMEJSON, "":
+		if !json.Valid(body) || !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			badPatch(c, errors.New("merge patch must be a JSON object"))
+			return patched, nil, false
+		}
+		result, err = jsonpatch.MergePatch(original, body)
+	case jsonPatchContentType:
+		var patch jsonpatch.Patch
+		if patch, err = jsonpatch.DecodePatch(body); err != nil {
+			badPatch(c, err)
+			return patched, nil, false
+		}
+		result, err = patch.Apply(original)
+	default:
+		problem.Abort(c, problem.UnsupportedMediaType, fmt.Sprintf("PATCH accepts %s or %s", mergePatchContentType, jsonPatchContentType))
+		return patched, nil, false
+	}
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		_ = c.Error(apperrors.Conflict(op, err))
+		return patched, nil, false
+	case err != nil:
+		_ = c.Error(apperrors.Validation(op, err))
+		return patched, nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		_ = c.Error(apperrors.Validation(op, err))
+		return patched, nil, false
+	}
+
+	changed, err = changedFields(original, result)
+	if err != nil {
+		_ = c.Error(apperrors.Validation(op, err))
+		return patched, nil, false
+	}
+	if err := validateFields(&patched, changed); err != nil {
+		_ = c.Error(apperrors.Validation(op, err))
+		return patched, nil, false
+	}
+	return patched, changed, true
+}
+
+func badPatch(c *gin.Context, err error) {
+	problem.Abort(c, problem.BadRequest, fmt.Sprintf("Invalid patch document: %v", err))
+}
+
+// changedFields returns the top-level keys whose values differ between two
+// JSON objects, including keys removed from or added to the second.
+func changedFields(before, after []byte) ([]string, error) {
+	var old, updated map[string]interface{}
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &updated); err != nil {
+		return nil, err
+	}
+	var changed []string
+	for key, value := range old {
+		if newValue, ok := updated[key]; !ok || !reflect.DeepEqual(value, newValue) {
+			changed = append(changed, key)
+		}
+	}
+	return changed, nil
+}
+
+// validateFields runs the binding rules of the struct fields whose JSON names
+// are listed, leaving the others alone so stored values that predate a rule do
+// not block unrelated edits.
+func validateFields(doc interface{}, jsonNames []string) error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok || len(jsonNames) == 0 {
+		return nil
+	}
+	docType := reflect.TypeOf(doc).Elem()
+	var fields []string
+	for _, name := range jsonNames {
+		for i := 0; i < docType.NumField(); i++ {
+			if strings.Split(docType.Field(i).Tag.Get("json"), ",")[0] == name {
+				fields = append(fields, docType.Field(i).Name)
+			}
+		}
+	}
+	if err := engine.StructPartial(doc, fields...); err != nil {
+		if invalid, ok := validation.Fields(err); ok {
+			return invalid
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/dto"
+	"backend/internal/problem"
+	"backend/internal/validation"
+	"backend/pkg/logging"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+// The helpers below each either succeed or produce the error response
+// themselves. When they report false the handler must return without writing
+// anything else.
+
+// pathID parses the :id path parameter, answering 400 when it is not a number.
+func pathID(c *gin.Context, logger *logrus.Logger, entity string) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		requestLog(c, logger).Warnf("Invalid %s ID: %s", entity, c.Param("id"))
+		problem.Abort(c, problem.BadRequest, fmt.Sprintf("Invalid %s ID: %s", entity, c.Param("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindJSON decodes the request body into a T and checks its binding tags.
+// Fields that break a rule or have the wrong JSON type are reported as 422
+// with one entry per field; a body that is not JSON at all is answered with
+// 400, and one cut off by a body limit with 413.
+func bindJSON[T any](c *gin.Context, logger *logrus.Logger, op string) (*T, bool) {
+	body := new(T)
+	if err := c.ShouldBindJSON(body); err != nil {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			requestLog(c, logger).Warnf("Request to %s too large: %v", op, err)
+			problem.Abort(c, problem.RequestTooLarge, fmt.Sprintf("Request body must be at most %d bytes", maxBytes.Limit))
+			return nil, false
+		}
+		if fields, ok := validation.Fields(err); ok {
+			requestLog(c, logger).Warnf("Invalid request to %s: %v", op, fields)
+			_ = c.Error(apperrors.Validation(op, fields))
+			return nil, false
+		}
+		requestLog(c, logger).Warnf("Failed to bind JSON: %v", err)
+		problem.Abort(c, problem.BadRequest, fmt.Sprintf("Failed to bind JSON: %v", err))
+		return nil, false
+	}
+	return body, true
+}
+
+// requestLog returns logger scoped to the request, carrying its request ID,
+// principal and trace.
+func requestLog(c *gin.Context, logger *logrus.Logger) *logrus.Entry {
+	return logging.FromContext(c.Request.Context(), logger)
+}
+
+// respond writes data and message as a dto.BaseResponse with the given status.
+func respond(c *gin.Context, status int, data interface{}, message string) {
+	c.JSON(status, dto.BaseResponse{
+		Data:       data,
+		Message:    message,
+		StatusCode: status,
+	})
+}
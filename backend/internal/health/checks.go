@@ -0,0 +1,58 @@
+package health
+
+import (
+	"backend/pkg/database"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Database pings the database connection.
+func Database(db *gorm.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+// Schema fails until every migration has been applied, so an instance never
+// serves traffic against a schema older than its code expects.
+func Schema(db *gorm.DB) Check {
+	return Check{Name: "schema", Run: func(ctx context.Context) error {
+		version, err := database.SchemaVersion(db.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		if want := database.LatestSchemaVersion(); version != want {
+			return fmt.Errorf("schema version is %d, want %d", version, want)
+		}
+		return nil
+	}}
+}
+
+// Reachable is an optional check that url answers HTTP requests. Any
+// response below 500, including 401 or 405, counts: it only proves the
+// service is up. Results are cached for cacheFor.
+func Reachable(name, url string, client *http.Client, cacheFor time.Duration) Check {
+	return Check{Name: name, Optional: true, CacheFor: cacheFor, Run: func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s answered %s", url, resp.Status)
+		}
+		return nil
+	}}
+}
@@ -0,0 +1,131 @@
+// Package health runs the readiness checks behind /readyz.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Statuses of a single check and of a whole report.
+const (
+	StatusOK = "ok"
+	// StatusDegraded means only optional checks failed; the service can still
+	// take traffic.
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// Check is one dependency the service needs.
+type Check struct {
+	Name string
+	// Optional checks are reported but never make the service unready.
+	Optional bool
+	// CacheFor reuses a result for this long, so slow or rate limited probes
+	// of external services do not run on every request.
+	CacheFor time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of one check. Error is only logged; it names hosts
+// and drivers, so it is never served.
+type Result struct {
+	Status    string    `json:"status" example:"ok"`
+	LatencyMS float64   `json:"latency_ms" example:"1.25"`
+	Error     string    `json:"-"`
+	Optional  bool      `json:"optional,omitempty"`
+	Cached    bool      `json:"cached,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of every check.
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Ready reports whether every required check passed.
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Checker runs a fixed set of checks, each bounded by a timeout. A timeout
+// that is not positive leaves checks bounded only by the request.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]Result
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout, now: time.Now, cache: make(map[string]Result)}
+}
+
+// Check runs every check concurrently and summarises them.
+func (c *Checker) Check(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(results))}
+	for i, result := range results {
+		report.Checks[c.checks[i].Name] = result
+		switch {
+		case result.Status == StatusOK:
+		case result.Optional:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		default:
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	if check.CacheFor > 0 {
+		c.mu.Lock()
+		cached, ok := c.cache[check.Name]
+		c.mu.Unlock()
+		if ok && c.now().Sub(cached.CheckedAt) < check.CacheFor {
+			cached.Cached = true
+			return cached
+		}
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	started := c.now()
+	err := check.Run(ctx)
+	result := Result{
+		Status:    StatusOK,
+		LatencyMS: float64(c.now().Sub(started).Microseconds()) / 1000,
+		Optional:  check.Optional,
+		CheckedAt: started,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	if check.CacheFor > 0 {
+		c.mu.Lock()
+		c.cache[check.Name] = result
+		c.mu.Unlock()
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package health
+
+import (
+	"backend/internal/config"
+	"backend/pkg/database"
+	"backend/pkg/logging"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func check(name string, optional bool, err error) Check {
+	return Check{Name: name, Optional: optional, Run: func(context.Context) error { return err }}
+}
+
+func TestChecker_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         []Check
+		expectedStatus string
+	}{
+		{
+			name:           "All pass",
+			checks:         []Check{check("database", false, nil), check("sms", true, nil)},
+			expectedStatus: StatusOK,
+		},
+		{
+			name:           "Optional check fails",
+			checks:         []Check{check("database", false, nil), check("sms", true, errors.New("timeout"))},
+			expectedStatus: StatusDegraded,
+		},
+		{
+			name:           "Required check fails",
+			checks:         []Check{check("database", false, errors.New("connection refused")), check("sms", true, nil)},
+			expectedStatus: StatusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewChecker(time.Second, tt.checks...).Check(context.Background())
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			assert.Equal(t, tt.expectedStatus != StatusFail, report.Ready())
+			assert.Len(t, report.Checks, len(tt.checks))
+		})
+	}
+}
+
+func TestChecker_ReportsErrors(t *testing.T) {
+	report := NewChecker(time.Second, check("database", false, errors.New("connection refused"))).Check(context.Background())
+
+	result := report.Checks["database"]
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "connection refused", result.Error)
+	assert.False(t, result.Optional)
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	slow := Check{Name: "slow", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	report := NewChecker(10*time.Millisecond, slow).Check(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestChecker_Cache(t *testing.T) {
+	runs := 0
+	probe := Check{Name: "sms", Optional: true, CacheFor: time.Minute, Run: func(context.Context) error {
+		runs++
+		return nil
+	}}
+	checker := NewChecker(time.Second, probe)
+	now := time.Now()
+	checker.now = func() time.Time { return now }
+
+	assert.False(t, checker.Check(context.Background()).Checks["sms"].Cached)
+	assert.True(t, checker.Check(context.Background()).Checks["sms"].Cached)
+	assert.Equal(t, 1, runs)
+
+	now = now.Add(time.Minute)
+	assert.False(t, checker.Check(context.Background()).Checks["sms"].Cached)
+	assert.Equal(t, 2, runs)
+}
+
+func TestDatabaseAndSchema(t *testing.T) {
+	logger := logging.GetLogger()
+	db, err := database.Open(config.Config{DBDriver: database.DriverSQLite, DatabaseURL: filepath.Join(t.TempDir(), "health.db")}, logger)
+	assert.NoError(t, err)
+	checker := NewChecker(time.Second, Database(db), Schema(db))
+
+	// Reachable, but nothing migrated yet.
+	report := checker.Check(context.Background())
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, StatusFail, report.Checks["schema"].Status)
+
+	assert.NoError(t, database.Migrate(db, logger))
+	assert.Equal(t, StatusOK, checker.Check(context.Background()).Status)
+
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlDB.Close())
+	assert.Equal(t, StatusFail, checker.Check(context.Background()).Checks["database"].Status)
+}
+
+func TestReachable(t *testing.T) {
+	status := http.StatusUnauthorized
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	probe := Reachable("auth", server.URL, server.Client(), 0)
+	assert.True(t, probe.Optional)
+	assert.NoError(t, probe.Run(context.Background()))
+
+	status = http.StatusBadGateway
+	assert.Error(t, probe.Run(context.Background()))
+
+	server.Close()
+	assert.Error(t, probe.Run(context.Background()))
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Purger permanently removes rows soft-deleted before a point in time.
+// Every repositories.Repository is one.
+type Purger interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PurgeTrash returns a job that permanently deletes rows that have been in
+// the trash for longer than retention. purgers maps a table name, used in log
+// lines, to its repository.
+func PurgeTrash(logger *logrus.Logger, retention time.Duration, purgers map[string]Purger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		before := time.Now().Add(-retention)
+		var errs []error
+		for name, purger := range purgers {
+			purged, err := purger.PurgeDeleted(ctx, before)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if purged > 0 {
+				logger.Infof("purged %d %s deleted before %s", purged, name, before.Format(time.RFC3339))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
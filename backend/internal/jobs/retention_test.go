@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"backend/pkg/logging"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type purgerFunc func(ctx context.Context, before time.Time) (int64, error)
+
+func (f purgerFunc) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return f(ctx, before)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	var cutoff time.Time
+	ok := purgerFunc(func(_ context.Context, before time.Time) (int64, error) {
+		cutoff = before
+		return 3, nil
+	})
+	failing := purgerFunc(func(context.Context, time.Time) (int64, error) {
+		return 0, errors.New("database is down")
+	})
+
+	job := PurgeTrash(logging.GetLogger(), 24*time.Hour, map[string]Purger{"orders": ok, "customers": failing})
+	err := job(context.Background())
+
+	assert.EqualError(t, err, "database is down")
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), cutoff, time.Minute)
+}
+
+func TestScheduler(t *testing.T) {
+	scheduler := NewScheduler(logging.GetLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := make(chan struct{}, 10)
+	scheduler.Every(ctx, "tick", time.Millisecond, func(context.Context) error {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	scheduler.Every(ctx, "disabled", 0, func(context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	})
+
+	<-runs
+	<-runs
+	cancel()
+	scheduler.Wait()
+}
+
+func TestScheduler_Shutdown(t *testing.T) {
+	scheduler := NewScheduler(logging.GetLogger())
+
+	started := make(chan struct{})
+	var once bool
+	scheduler.Every(context.Background(), "slow", time.Hour, func(ctx context.Context) error {
+		if !once {
+			once = true
+			close(started)
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, scheduler.Shutdown(ctx))
+
+	// A job that ignores cancellation makes Shutdown give up at the deadline.
+	stuck := NewScheduler(logging.GetLogger())
+	release := make(chan struct{})
+	defer close(release)
+	stuck.Every(context.Background(), "stuck", time.Hour, func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, stuck.Shutdown(ctx), context.DeadlineExceeded)
+}
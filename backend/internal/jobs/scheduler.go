@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduler runs background jobs on fixed intervals until their context is
+// cancelled or the scheduler is shut down.
+type Scheduler struct {
+	logger *logrus.Logger
+	wg     sync.WaitGroup
+	stop   context.Context
+	cancel context.CancelFunc
+}
+
+func NewScheduler(logger *logrus.Logger) *Scheduler {
+	stop, cancel := context.WithCancel(context.Background())
+	return &Scheduler{logger: logger, stop: stop, cancel: cancel}
+}
+
+// Every runs fn once immediately and then every interval in its own
+// goroutine. Failures are logged and the job keeps running. A non-positive
+// interval disables the job.
+func (s *Scheduler) Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		s.logger.Infof("job %s is disabled", name)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	release := context.AfterFunc(s.stop, cancel)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer release()
+		defer cancel()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Warnf("job %s failed: %v", name, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until every job has returned after its context was cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Shutdown cancels every job and waits for running ones to finish, giving up
+// with ctx's error when ctx is done first.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
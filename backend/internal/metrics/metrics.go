@@ -0,0 +1,90 @@
+// Package metrics defines the Prometheus metrics the service exports on
+// /metrics and the instrumentation that records them.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests, by method, route template and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time taken by database statements, by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	SMSSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sms_messages_total",
+		Help: "SMS messages sent, by outcome (success or failure).",
+	}, []string{"outcome"})
+
+	TokenCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_token_cache_requests_total",
+		Help: "Access token validations answered from the cache (hit) or by the OAuth provider (miss).",
+	}, []string{"result"})
+
+	OrdersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "orders_created_total",
+		Help: "Orders created.",
+	})
+
+	CustomersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "customers_created_total",
+		Help: "Customers created.",
+	})
+)
+
+// unmatchedRoute labels requests that matched no route, so scans of random
+// paths cannot blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// Outcome is the label value for the result of an operation.
+func Outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// TokenCacheLookup counts an access token cache lookup as a hit or a miss.
+func TokenCacheLookup(hit bool) {
+	if hit {
+		TokenCacheRequests.WithLabelValues("hit").Inc()
+		return
+	}
+	TokenCacheRequests.WithLabelValues("miss").Inc()
+}
+
+// Middleware records the duration of every request under its route
+// template, e.g. /api/v1/orders/:id rather than /api/v1/orders/42.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(started).Seconds())
+	}
+}
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/metrics", Handler())
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/nowhere"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/orders/:id",status="204"} 2`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, `route="/orders/1"`)
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "metrics.db")), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(GormPlugin{}))
+
+	type widget struct {
+		ID   uint
+		Name string
+	}
+	assert.NoError(t, db.AutoMigrate(&widget{}))
+	creates, queries := sampleCount(t, "create", "widgets"), sampleCount(t, "query", "widgets")
+
+	assert.NoError(t, db.Create(&widget{Name: "a"}).Error)
+	var widgets []widget
+	assert.NoError(t, db.Find(&widgets).Error)
+
+	assert.Equal(t, creates+1, sampleCount(t, "create", "widgets"))
+	assert.Equal(t, queries+1, sampleCount(t, "query", "widgets"))
+}
+
+func sampleCount(t *testing.T, labels ...string) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, DBQueryDuration.WithLabelValues(labels...).(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
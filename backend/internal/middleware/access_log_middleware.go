@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// AccessLog writes one structured line per request once it has been served,
+// with the request-scoped fields (request ID, principal, trace) and the
+// route, status, latency and response size. Server errors are logged at
+// error level and client errors at warning level. Requests to quietPaths,
+// such as health probes, are only logged at debug level.
+func AccessLog(logger *logrus.Logger, quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := logging.FromContext(c.Request.Context(), logger).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+			"path":       c.Request.URL.Path,
+			"status":     status,
+			"latency_ms": float64(time.Since(started).Microseconds()) / 1000,
+			"bytes":      max(c.Writer.Size(), 0),
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		})
+
+		level := logrus.InfoLevel
+		switch {
+		case quiet[c.Request.URL.Path]:
+			level = logrus.DebugLevel
+		case status >= http.StatusInternalServerError:
+			level = logrus.ErrorLevel
+		case status >= http.StatusBadRequest:
+			level = logrus.WarnLevel
+		}
+		entry.Logf(level, "%s %s %d", c.Request.Method, c.Request.URL.Path, status)
+	}
+}
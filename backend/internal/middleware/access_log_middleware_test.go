@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"backend/pkg/logging"
+	"backend/pkg/requestid"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedRoute string
+		expectedLevel string
+		expectLogged  bool
+	}{
+		{name: "Success", path: "/orders/7", expectedRoute: "/orders/:id", expectedLevel: "info", expectLogged: true},
+		{name: "Server error", path: "/fail", expectedRoute: "/fail", expectedLevel: "error", expectLogged: true},
+		{name: "No route", path: "/missing", expectedRoute: "", expectedLevel: "warning", expectLogged: true},
+		{name: "Quiet path below level", path: "/healthz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&out)
+			logger.SetFormatter(&logrus.JSONFormatter{})
+
+			var handlerLine bytes.Buffer
+			router := gin.New()
+			router.Use(RequestID())
+			router.Use(AccessLog(logger, "/healthz"))
+			router.GET("/orders/:id", func(c *gin.Context) {
+				// Handlers log through the request-scoped logger.
+				handlerLogger := logrus.New()
+				handlerLogger.SetOutput(&handlerLine)
+				handlerLogger.SetFormatter(&logrus.JSONFormatter{})
+				logging.FromContext(c.Request.Context(), handlerLogger).Info("loading order")
+				c.String(http.StatusOK, "order")
+			})
+			router.GET("/fail", func(c *gin.Context) {
+				c.Status(http.StatusInternalServerError)
+			})
+			router.GET("/healthz", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			req.Header.Set(requestid.Header, "req-1")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !tt.expectLogged {
+				assert.Empty(t, out.String())
+				return
+			}
+			var line map[string]interface{}
+			assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+			assert.Equal(t, tt.expectedLevel, line["level"])
+			assert.Equal(t, "req-1", line["request_id"])
+			assert.Equal(t, "GET", line["method"])
+			assert.Equal(t, tt.expectedRoute, line["route"])
+			assert.Equal(t, tt.path, line["path"])
+			assert.Contains(t, line, "latency_ms")
+			assert.Contains(t, line, "status")
+
+			if tt.path == "/orders/7" {
+				assert.Equal(t, float64(len("order")), line["bytes"])
+				var handlerEntry map[string]interface{}
+				assert.NoError(t, json.Unmarshal(handlerLine.Bytes(), &handlerEntry))
+				assert.Equal(t, "req-1", handlerEntry["request_id"])
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"backend/internal/config"
+	"backend/internal/problem"
 	"backend/pkg/authentication"
+	"backend/pkg/logging"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"github.com/sirupsen/logrus"
 	"strings"
 )
 
@@ -13,30 +16,59 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
-			c.Abort()
+			unauthorized(c, "Authorization token required")
 			return
 		}
 
 		// Extract the token from the header
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
-			c.Abort()
+			unauthorized(c, "Invalid Authorization header format")
 			return
 		}
 
 		accessToken := tokenParts[1]
 
 		// Validate the token
-		valid, err := authentication.ValidateToken(accessToken)
-		if err != nil || !valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+		principal, err := authentication.Authenticate(c.Request.Context(), accessToken)
+		if err != nil {
+			unauthorized(c, "Invalid or expired token")
 			return
 		}
+		principal.Admin = isAdmin(principal, config.AppConfig.AdminUsers)
+		ctx := authentication.WithPrincipal(c.Request.Context(), principal)
+		c.Request = c.Request.WithContext(logging.WithFields(ctx, logrus.Fields{"principal": principal.Login}))
 
 		// Proceed to the next middleware/handler
 		c.Next()
 	}
 }
+
+// RequireAdmin rejects requests whose principal is not an administrator. It
+// must run after AuthMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := authentication.PrincipalFrom(c.Request.Context())
+		if principal == nil || !principal.Admin {
+			problem.Abort(c, problem.Forbidden, "Administrator access required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// unauthorized rejects the request with 401, asking for a bearer token.
+func unauthorized(c *gin.Context, detail string) {
+	c.Header("WWW-Authenticate", "Bearer")
+	problem.Abort(c, problem.Unauthorized, detail)
+}
+
+// isAdmin reports whether the principal's login or user ID is listed in admins.
+func isAdmin(principal *authentication.Principal, admins []string) bool {
+	for _, admin := range admins {
+		if strings.EqualFold(admin, principal.Login) || admin == principal.UserID {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"backend/pkg/authentication"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -22,19 +23,19 @@ func TestAuthMiddleware(t *testing.T) {
 			name:           "No Authorization header",
 			authHeader:     "",
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Authorization token required"}`,
+			expectedBody:   `{"type":"https://github.com/paulodhiambo/savannah/blob/main/docs/problems.md#unauthorized","title":"Unauthorized","status":401,"detail":"Authorization token required","instance":"/test"}`,
 		},
 		{
 			name:           "Invalid Authorization header format",
 			authHeader:     "InvalidFormat",
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Invalid Authorization header format"}`,
+			expectedBody:   `{"type":"https://github.com/paulodhiambo/savannah/blob/main/docs/problems.md#unauthorized","title":"Unauthorized","status":401,"detail":"Invalid Authorization header format","instance":"/test"}`,
 		},
 		{
 			name:           "Invalid token",
 			authHeader:     "Bearer invalid-token",
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Invalid or expired token"}`,
+			expectedBody:   `{"type":"https://github.com/paulodhiambo/savannah/blob/main/docs/problems.md#unauthorized","title":"Unauthorized","status":401,"detail":"Invalid or expired token","instance":"/test"}`,
 		},
 	}
 
@@ -67,7 +68,56 @@ func TestAuthMiddleware(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
 			// Assert the response body
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
 			assert.JSONEq(t, tt.expectedBody, w.Body.String())
 		})
 	}
 }
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		principal      *authentication.Principal
+		expectedStatus int
+	}{
+		{name: "Unauthenticated", expectedStatus: http.StatusForbidden},
+		{name: "Staff", principal: &authentication.Principal{Login: "staff"}, expectedStatus: http.StatusForbidden},
+		{name: "Admin", principal: &authentication.Principal{Login: "boss", Admin: true}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(authentication.WithPrincipal(c.Request.Context(), tt.principal))
+				}
+			})
+			router.GET("/test", RequireAdmin(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), `"detail":"Administrator access required"`)
+			}
+		})
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	admins := []string{"PaulOdhiambo", "12345"}
+
+	assert.True(t, isAdmin(&authentication.Principal{Login: "paulodhiambo"}, admins))
+	assert.True(t, isAdmin(&authentication.Principal{UserID: "12345", Login: "someone"}, admins))
+	assert.False(t, isAdmin(&authentication.Principal{UserID: "1", Login: "someone"}, admins))
+	assert.False(t, isAdmin(&authentication.Principal{Login: "paulodhiambo"}, nil))
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"backend/internal/problem"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// BodyLimit rejects request bodies larger than limit bytes with 413. A body
+// that declares its length is rejected before any of it is read; one that
+// does not fails the first read past limit, which tooLarge recognises.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			abortTooLarge(c, limit)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// tooLarge answers 413 and reports true when err comes from reading a body
+// past the limit set by BodyLimit.
+func tooLarge(c *gin.Context, err error) bool {
+	var maxBytes *http.MaxBytesError
+	if !errors.As(err, &maxBytes) {
+		return false
+	}
+	abortTooLarge(c, maxBytes.Limit)
+	return true
+}
+
+func abortTooLarge(c *gin.Context, limit int64) {
+	problem.Abort(c, problem.RequestTooLarge, fmt.Sprintf("Request body must be at most %d bytes", limit))
+}
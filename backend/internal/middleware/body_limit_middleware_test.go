@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"backend/internal/problem"
+	"backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		chunked        bool
+		expectedStatus int
+		expectedReads  int
+	}{
+		{name: "Within the limit", body: `{"quantity":1}`, expectedStatus: http.StatusCreated, expectedReads: 1},
+		{name: "Declared length over the limit", body: strings.Repeat("x", 33), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Undeclared length over the limit", body: strings.Repeat("x", 33), chunked: true, expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reads := 0
+			router := gin.New()
+			router.Use(ErrorHandler(logging.GetLogger()))
+			router.POST("/orders", BodyLimit(32), Idempotency(newIdempotencyStore(t), time.Hour, time.Minute, logging.GetLogger()), func(c *gin.Context) {
+				reads++
+				c.Status(http.StatusCreated)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.Body = io.NopCloser(strings.NewReader(tt.body))
+				req.ContentLength = -1
+			}
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedReads, reads)
+			if tt.expectedStatus == http.StatusRequestEntityTooLarge {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
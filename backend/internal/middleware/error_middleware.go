@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"backend/internal/problem"
+	"backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// ErrorHandler renders the last error a handler attached with c.Error as a
+// problem+json response, mapping apperrors kinds to their problem type.
+// Handlers that already wrote a response are left alone.
+func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		p := problem.FromError(c, err)
+		switch p.Status {
+		case http.StatusInternalServerError:
+			logging.FromContext(c.Request.Context(), logger).Errorf("unhandled error on %s %s: %v", c.Request.Method, c.FullPath(), err)
+		case http.StatusServiceUnavailable:
+			logging.FromContext(c.Request.Context(), logger).Warnf("dependency unavailable on %s %s: %v", c.Request.Method, c.FullPath(), err)
+		}
+		problem.Respond(c, p)
+	}
+}
+
+// Recovery turns a panicking handler into a 500 problem response.
+func Recovery(logger *logrus.Logger) gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		logging.FromContext(c.Request.Context(), logger).Errorf("panic on %s %s: %v", c.Request.Method, c.FullPath(), recovered)
+		problem.Abort(c, problem.Internal, "")
+	})
+}
+
+// NotFound answers requests no route matches.
+func NotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		problem.Abort(c, problem.NotFound, "No route matches "+c.Request.Method+" "+c.Request.URL.Path)
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/dto"
+	"backend/internal/problem"
+	"backend/internal/validation"
+	"backend/pkg/logging"
+	"backend/pkg/requestid"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedType    string
+		expectedMessage string
+	}{
+		{
+			name:            "Not found",
+			err:             apperrors.NotFound("get order by ID", errors.New("record not found")),
+			expectedStatus:  http.StatusNotFound,
+			expectedType:    problem.TypeBase + "not-found",
+			expectedMessage: "record not found",
+		},
+		{
+			name:            "Conflict",
+			err:             apperrors.Conflict("create customer", errors.New("duplicated key not allowed")),
+			expectedStatus:  http.StatusConflict,
+			expectedType:    problem.TypeBase + "conflict",
+			expectedMessage: "duplicated key not allowed",
+		},
+		{
+			name:            "Validation",
+			err:             apperrors.Validation("create order", errors.New("violates check constraint")),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedType:    problem.TypeBase + "validation",
+			expectedMessage: "violates check constraint",
+		},
+		{
+			name:            "Precondition failed",
+			err:             apperrors.PreconditionFailed("update order", errors.New("order was modified by another request")),
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedType:    problem.TypeBase + "precondition-failed",
+			expectedMessage: "order was modified by another request",
+		},
+		{
+			name:            "Unavailable",
+			err:             apperrors.Unavailable("get all orders", errors.New("dial tcp 10.0.3.7:5432: connect: connection refused")),
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedType:    problem.TypeBase + "unavailable",
+			expectedMessage: "A dependency did not answer in time. Retry later.",
+		},
+		{
+			name:            "Unclassified error hides details",
+			err:             errors.New("pq: something internal"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedType:    problem.TypeBase + "internal",
+			expectedMessage: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestID())
+			router.Use(ErrorHandler(logging.GetLogger()))
+			router.GET("/test", func(c *gin.Context) {
+				_ = c.Error(tt.err)
+			})
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.Header.Set(requestid.Header, "req-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+			var response dto.Problem
+			_ = json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, tt.expectedType, response.Type)
+			assert.Equal(t, http.StatusText(tt.expectedStatus), response.Title)
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			assert.Equal(t, tt.expectedMessage, response.Detail)
+			assert.Equal(t, "/test", response.Instance)
+			assert.Equal(t, "req-1", response.RequestID)
+		})
+	}
+}
+
+func TestErrorHandler_FieldErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler(logging.GetLogger()))
+	router.POST("/test", func(c *gin.Context) {
+		_ = c.Error(apperrors.Validation("create order", validation.Errors{
+			{Field: "quantity", Code: "gt", Message: "quantity must be greater than 0"},
+		}))
+	})
+
+	req, _ := http.NewRequest("POST", "/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, []dto.FieldError{{Field: "quantity", Code: "gt", Message: "quantity must be greater than 0"}}, response.Errors)
+}
+
+func TestRecoveryAndNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Recovery(logging.GetLogger()))
+	router.NoRoute(NotFound())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedDetail string
+	}{
+		{path: "/panic", expectedStatus: http.StatusInternalServerError},
+		{path: "/missing", expectedStatus: http.StatusNotFound, expectedDetail: "No route matches GET /missing"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.expectedStatus, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		var response dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, tt.expectedStatus, response.Status)
+		assert.Equal(t, tt.expectedDetail, response.Detail)
+	}
+}
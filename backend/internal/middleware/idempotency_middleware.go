@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/logging"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	inFlightRetryAfterSeconds = "1"
+)
+
+// Idempotency makes a route safe to retry. The first request carrying a given
+// Idempotency-Key header runs normally and its response is stored for ttl;
+// retries with the same key and payload get that response replayed. Reusing a
+// key with a different payload is rejected with 422 and a retry that arrives
+// while the original is still running gets 409. Keys are scoped to the caller's
+// Authorization header and the route, so clients cannot collide with each other.
+// A request that has not finished within lease, because its process died, no
+// longer blocks retries. Requests without the header are passed through
+// untouched.
+func Idempotency(store repositories.IdempotencyRepositoryImpl, ttl, lease time.Duration, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, apperrors.Validation("check idempotency key", errors.New("Idempotency-Key must be at most 255 characters")))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			if tooLarge(c, err) {
+				return
+			}
+			abortWithError(c, apperrors.Validation("read request body", err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := &models.IdempotencyKey{
+			Scope:       hash([]byte(c.GetHeader("Authorization")), []byte(c.Request.Method+" "+c.FullPath())),
+			Key:         key,
+			RequestHash: hash(body),
+			LockedUntil: time.Now().Add(lease),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		existing, err := store.Reserve(c.Request.Context(), record)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if existing != nil {
+			replay(c, existing, record.RequestHash)
+			return
+		}
+
+		// The outcome is recorded even if the client has already hung up: that
+		// retry is exactly what the stored response is for.
+		ctx := context.WithoutCancel(c.Request.Context())
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			err := store.Release(ctx, record)
+			switch {
+			case errors.Is(err, repositories.ErrLeaseLost):
+				logging.FromContext(ctx, logger).Warnf("idempotency key %q was taken over by a retry before it was released", key)
+			case err != nil:
+				logging.FromContext(ctx, logger).Errorf("failed to release idempotency key %q: %v", key, err)
+			}
+		}()
+
+		c.Next()
+
+		// Only responses the handler wrote itself are stored. Server errors and
+		// errors still waiting for ErrorHandler free the key for another attempt.
+		if !recorder.Written() || recorder.Status() >= http.StatusInternalServerError || len(c.Errors) > 0 {
+			return
+		}
+		record.StatusCode = recorder.Status()
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.ResponseBody = recorder.body.Bytes()
+		err = store.Complete(ctx, record)
+		switch {
+		case errors.Is(err, repositories.ErrLeaseLost):
+			// The retry that took over stores its own response.
+			logging.FromContext(ctx, logger).Warnf("idempotency key %q was taken over by a retry before its response was stored", key)
+			completed = true
+		case err != nil:
+			logging.FromContext(ctx, logger).Errorf("failed to store response for idempotency key %q: %v", key, err)
+		default:
+			completed = true
+		}
+	}
+}
+
+// replay answers a retry from the record stored by the original request.
+func replay(c *gin.Context, existing *models.IdempotencyKey, requestHash string) {
+	switch {
+	case existing.RequestHash != requestHash:
+		abortWithError(c, apperrors.Validation("check idempotency key", errors.New("Idempotency-Key was already used with a different request payload")))
+	case !existing.Completed():
+		c.Header("Retry-After", inFlightRetryAfterSeconds)
+		abortWithError(c, apperrors.Conflict("check idempotency key", errors.New("a request with this Idempotency-Key is still being processed")))
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(existing.StatusCode, existing.ContentType, existing.ResponseBody)
+		c.Abort()
+	}
+}
+
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of everything written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/database"
+	"backend/pkg/logging"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyStore(t *testing.T) repositories.IdempotencyRepositoryImpl {
+	t.Helper()
+	logger := logging.GetLogger()
+	db, err := database.Open(config.Config{DBDriver: database.DriverSQLite, DatabaseURL: filepath.Join(t.TempDir(), "test.db")}, logger)
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate(db, logger))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return repositories.NewIdempotencyRepository(db, logger)
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	send := func(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	newRouter := func(store repositories.IdempotencyRepositoryImpl, calls *int, status int) *gin.Engine {
+		router := gin.New()
+		router.Use(ErrorHandler(logging.GetLogger()))
+		router.POST("/orders", Idempotency(store, time.Hour, time.Minute, logging.GetLogger()), func(c *gin.Context) {
+			*calls++
+			c.JSON(status, gin.H{"call": *calls})
+		})
+		return router
+	}
+
+	t.Run("Replays the stored response", func(t *testing.T) {
+		calls := 0
+		router := newRouter(newIdempotencyStore(t), &calls, http.StatusCreated)
+
+		first := send(router, "key-1", `{"quantity":1}`)
+		second := send(router, "key-1", `{"quantity":1}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("Rejects a reused key with a different payload", func(t *testing.T) {
+		calls := 0
+		router := newRouter(newIdempotencyStore(t), &calls, http.StatusCreated)
+
+		send(router, "key-1", `{"quantity":1}`)
+		w := send(router, "key-1", `{"quantity":2}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("Rejects a duplicate while the original is in flight", func(t *testing.T) {
+		calls := 0
+		store := newIdempotencyStore(t)
+		router := newRouter(store, &calls, http.StatusCreated)
+
+		// Hold the reservation a concurrent original request would have made.
+		_, err := store.Reserve(context.Background(), &models.IdempotencyKey{
+			Scope:       hash([]byte("Bearer token"), []byte("POST /orders")),
+			Key:         "key-1",
+			RequestHash: hash([]byte(`{"quantity":1}`)),
+			LockedUntil: time.Now().Add(time.Minute),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		w := send(router, "key-1", `{"quantity":1}`)
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Retries a request whose process died", func(t *testing.T) {
+		calls := 0
+		store := newIdempotencyStore(t)
+		router := newRouter(store, &calls, http.StatusCreated)
+
+		// A reservation left behind by a crash, whose lease has run out.
+		_, err := store.Reserve(context.Background(), &models.IdempotencyKey{
+			Scope:       hash([]byte("Bearer token"), []byte("POST /orders")),
+			Key:         "key-1",
+			RequestHash: hash([]byte(`{"quantity":1}`)),
+			LockedUntil: time.Now().Add(-time.Second),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		first := send(router, "key-1", `{"quantity":1}`)
+		second := send(router, "key-1", `{"quantity":1}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("Server errors release the key", func(t *testing.T) {
+		calls := 0
+		router := newRouter(newIdempotencyStore(t), &calls, http.StatusInternalServerError)
+
+		send(router, "key-1", `{"quantity":1}`)
+		w := send(router, "key-1", `{"quantity":1}`)
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("Requests without a key are not deduplicated", func(t *testing.T) {
+		calls := 0
+		router := newRouter(newIdempotencyStore(t), &calls, http.StatusCreated)
+
+		send(router, "", `{"quantity":1}`)
+		send(router, "", `{"quantity":1}`)
+
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"backend/internal/problem"
+	"backend/internal/ratelimit"
+	"backend/pkg/authentication"
+	"backend/pkg/logging"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RateLimitByIP takes a token from the client IP's bucket for the route's
+// policy. It runs before authentication, so it also bounds requests with
+// made-up tokens, which would otherwise each cost a token lookup.
+func RateLimitByIP(store ratelimit.Store, policies ratelimit.Policies, logger *logrus.Logger) gin.HandlerFunc {
+	return rateLimit(store, policies, logger, func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	})
+}
+
+// RateLimitByUser takes a token from the authenticated user's bucket for the
+// route's policy, so a user's limit holds across tokens and addresses. It
+// must run after AuthMiddleware; requests without a principal are keyed by
+// client IP.
+func RateLimitByUser(store ratelimit.Store, policies ratelimit.Policies, logger *logrus.Logger) gin.HandlerFunc {
+	return rateLimit(store, policies, logger, func(c *gin.Context) string {
+		if principal := authentication.PrincipalFrom(c.Request.Context()); principal != nil {
+			return "user:" + principal.UserID
+		}
+		return "ip:" + c.ClientIP()
+	})
+}
+
+// rateLimit answers 429 once the bucket of the caller named by key is empty.
+// Every limited response carries RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy; rejections add Retry-After. When the
+// store fails the request is let through.
+func rateLimit(store ratelimit.Store, policies ratelimit.Policies, logger *logrus.Logger, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, policy, ok := policies.For(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		decision, err := store.Take(c.Request.Context(), name+"|"+key(c), policy)
+		if err != nil {
+			logging.FromContext(c.Request.Context(), logger).Warnf("rate limit store failed, allowing request: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		c.Header("RateLimit-Policy", policy.String())
+		if !decision.Allowed {
+			retryAfter := max(ceilSeconds(decision.RetryAfter), 1)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			problem.Abort(c, problem.TooManyRequests, fmt.Sprintf("Rate limit of %d requests per %s exceeded, retry in %d seconds", policy.Limit, policy.Period, retryAfter))
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
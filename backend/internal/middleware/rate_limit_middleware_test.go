@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"backend/internal/dto"
+	"backend/internal/problem"
+	"backend/internal/ratelimit"
+	"backend/pkg/authentication"
+	"backend/pkg/logging"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Policy) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("connection refused")
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policies := ratelimit.Policies{Routes: map[string]ratelimit.Policy{
+		"POST /orders": {Limit: 2, Period: time.Minute},
+	}}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("Authorization"); user != "" {
+			c.Request = c.Request.WithContext(authentication.WithPrincipal(c.Request.Context(), &authentication.Principal{UserID: user}))
+		}
+	})
+	router.Use(RateLimitByUser(ratelimit.NewMemoryStore(), policies, logging.GetLogger()))
+	router.POST("/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(method, user string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/orders", nil)
+		req.Header.Set("Authorization", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "alice")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, http.StatusCreated, send("POST", "alice").Code)
+
+	w = send("POST", "alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	var response dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, problem.TooManyRequests.URI, response.Type)
+
+	// Another user, and routes without a policy, are not affected.
+	assert.Equal(t, http.StatusCreated, send("POST", "bob").Code)
+	w = send("GET", "alice")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitByIP_RotatingInvalidTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limit := ratelimit.Policy{Limit: 2, Period: time.Minute}
+	store := ratelimit.NewMemoryStore()
+	router := gin.New()
+	router.Use(RateLimitByIP(store, ratelimit.Policies{Default: &limit}, logging.GetLogger()))
+	router.Use(AuthMiddleware(), RateLimitByUser(store, ratelimit.Policies{Default: &limit}, logging.GetLogger()))
+	router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var codes []int
+	for _, token := range []string{"fake-1", "fake-2", "fake-3"} {
+		req, _ := http.NewRequest("GET", "/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
+
+func TestRateLimitByIP_ForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		expectedCodes  []int
+	}{
+		{name: "Spoofed by an untrusted peer", expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}},
+		{name: "Set by a trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := ratelimit.Policy{Limit: 2, Period: time.Minute}
+			router := gin.New()
+			assert.NoError(t, router.SetTrustedProxies(tt.trustedProxies))
+			router.Use(RateLimitByIP(ratelimit.NewMemoryStore(), ratelimit.Policies{Default: &limit}, logging.GetLogger()))
+			router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var codes []int
+			for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+				// httptest requests come from 192.0.2.1.
+				req := httptest.NewRequest("GET", "/orders", nil)
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				codes = append(codes, w.Code)
+			}
+			assert.Equal(t, tt.expectedCodes, codes)
+		})
+	}
+}
+
+func TestRateLimit_StoreFailureAllows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limit := ratelimit.Policy{Limit: 1, Period: time.Minute}
+	router := gin.New()
+	router.Use(RateLimitByIP(failingStore{}, ratelimit.Policies{Default: &limit}, logging.GetLogger()))
+	router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest("GET", "/orders", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
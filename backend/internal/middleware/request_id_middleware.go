@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"backend/pkg/logging"
+	"backend/pkg/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const maxRequestIDLength = 64
+
+// RequestID tags every request with an ID, reusing a well-formed X-Request-ID
+// sent by the client or a proxy and generating one otherwise. The ID is put
+// in the request context and its log fields, and echoed in the response
+// header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !validRequestID(id) {
+			id = requestid.New()
+		}
+		ctx := requestid.With(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(logging.WithFields(ctx, logrus.Fields{"request_id": id}))
+		c.Header(requestid.Header, id)
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs made of letters, digits and the
+// punctuation common in UUIDs and trace IDs, so the value is safe to log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
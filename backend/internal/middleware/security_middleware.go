@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"backend/pkg/requestid"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// corsAllowedHeaders are the request headers browsers may send cross-origin.
+var corsAllowedHeaders = []string{
+	"Authorization", "Content-Type", "If-Match", "If-None-Match", "Idempotency-Key", requestid.Header, "traceparent", "tracestate",
+}
+
+// corsExposedHeaders are the response headers cross-origin scripts may read.
+var corsExposedHeaders = []string{
+	"ETag", "Location", "Retry-After", "Idempotent-Replayed", requestid.Header,
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+}
+
+// CORS lets pages from allowedOrigins call the API with allowedMethods and
+// answers their preflight requests. An origin of "*" allows any origin; it
+// cannot be combined with credentials.
+func CORS(allowedOrigins, allowedMethods []string, allowCredentials bool) gin.HandlerFunc {
+	cfg := cors.Config{
+		AllowMethods:     allowedMethods,
+		AllowHeaders:     corsAllowedHeaders,
+		ExposeHeaders:    corsExposedHeaders,
+		AllowCredentials: allowCredentials,
+		MaxAge:           12 * time.Hour,
+	}
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			cfg.AllowAllOrigins = true
+		}
+	}
+	if !cfg.AllowAllOrigins {
+		cfg.AllowOrigins = allowedOrigins
+	}
+	return cors.New(cfg)
+}
+
+// SecurityHeaders sets headers that keep browsers from sniffing content
+// types, framing responses or leaking URLs in the Referer. Strict-Transport-
+// Security is only sent on HTTPS requests, served directly or behind a proxy
+// that sets X-Forwarded-Proto, and only when hstsMaxAge is positive.
+func SecurityHeaders(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := "max-age=" + strconv.Itoa(int(hstsMaxAge.Seconds())) + "; includeSubDomains"
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Content-Security-Policy", "frame-ancestors 'none'")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Cross-Origin-Opener-Policy", "same-origin")
+		if hstsMaxAge > 0 && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		tls          bool
+		forwarded    string
+		hstsMaxAge   time.Duration
+		expectedHSTS string
+	}{
+		{name: "Plain HTTP", hstsMaxAge: time.Hour},
+		{name: "TLS", tls: true, hstsMaxAge: time.Hour, expectedHSTS: "max-age=3600; includeSubDomains"},
+		{name: "HTTPS behind a proxy", forwarded: "https", hstsMaxAge: time.Hour, expectedHSTS: "max-age=3600; includeSubDomains"},
+		{name: "HSTS disabled", tls: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SecurityHeaders(tt.hstsMaxAge))
+			router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwarded)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+			assert.Equal(t, tt.expectedHSTS, w.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CORS([]string{"https://app.example.com"}, []string{"GET", "POST"}, true))
+	router.NoRoute(NotFound())
+	router.GET("/orders", func(c *gin.Context) {
+		c.Header("ETag", `"1"`)
+		c.Status(http.StatusOK)
+	})
+
+	// Preflight requests match no route and are answered by the middleware.
+	req, _ := http.NewRequest("OPTIONS", "/orders", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Authorization, Idempotency-Key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Idempotency-Key")
+
+	req, _ = http.NewRequest("GET", "/orders", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "Etag")
+
+	req, _ = http.NewRequest("GET", "/orders", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// AuditActions lists every action recorded in the audit log.
+var AuditActions = []string{AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditPurge}
+
+// AuditLog is an append-only record of one change to one row. Changes maps
+// each field that changed to its old and new value. RequestID is NULL for
+// changes made outside a request, such as by background jobs.
+type AuditLog struct {
+	ID        uint                   `json:"id" gorm:"primarykey"`
+	Entity    string                 `json:"entity" gorm:"size:64;not null;index:idx_audit_logs_entity"`
+	EntityID  uint                   `json:"entity_id" gorm:"not null;index:idx_audit_logs_entity"`
+	Action    string                 `json:"action" gorm:"size:16;not null"`
+	Actor     string                 `json:"actor" gorm:"size:255;not null;index"`
+	RequestID string                 `json:"request_id,omitempty" gorm:"size:64;index;default:null"`
+	Changes   map[string]FieldChange `json:"changes" gorm:"serializer:json"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index"`
+}
+
+// FieldChange holds the JSON encoded value of a field before and after a
+// change. From is absent for created rows and To for deleted ones.
+type FieldChange struct {
+	From json.RawMessage `json:"from,omitempty" swaggertype:"object"`
+	To   json.RawMessage `json:"to,omitempty" swaggertype:"object"`
+}
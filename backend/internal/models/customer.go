@@ -1,12 +1,20 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"regexp"
+)
+
+// customerCodePattern accepts 2 to 32 uppercase letters, digits and hyphens,
+// starting with a letter or digit, e.g. CUST-001.
+var customerCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{1,31}$`)
 
 type Customer struct {
 	gorm.Model
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Code string `json:"code"`
+	Versioned
+	Name  string `json:"name"`
+	Code  string `json:"code"`
+	Phone string `json:"phone,omitempty"`
 }
 
 // NewCustomer creates a new Customer instance
@@ -16,3 +24,9 @@ func NewCustomer(name, code string) *Customer {
 		Code: code,
 	}
 }
+
+// ValidCustomerCode reports whether code has the format customer codes must
+// follow. Codes are unique among customers that are not deleted.
+func ValidCustomerCode(code string) bool {
+	return customerCodePattern.MatchString(code)
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 	"testing"
 	"time"
 )
@@ -19,12 +20,21 @@ func TestNewCustomer(t *testing.T) {
 
 func TestCustomerFields(t *testing.T) {
 	customer := &Customer{
-		ID:   1,
-		Name: "Jane Doe",
-		Code: "C124",
+		Model: gorm.Model{ID: 1},
+		Name:  "Jane Doe",
+		Code:  "C124",
 	}
 
-	assert.Equal(t, 1, customer.ID)
+	assert.Equal(t, uint(1), customer.ID)
 	assert.Equal(t, "Jane Doe", customer.Name)
 	assert.Equal(t, "C124", customer.Code)
 }
+
+func TestValidCustomerCode(t *testing.T) {
+	for _, code := range []string{"C123", "CUST-001", "AB"} {
+		assert.True(t, ValidCustomerCode(code), code)
+	}
+	for _, code := range []string{"", "C", "c123", "-C123", "C 123", "C123456789012345678901234567890123"} {
+		assert.False(t, ValidCustomerCode(code), code)
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// IdempotencyKey remembers the outcome of a request sent with an
+// Idempotency-Key header so retries can be answered without re-running it.
+// A row without a StatusCode is a reservation for a request still in flight,
+// held until LockedUntil. A reservation whose lease ran out belongs to a
+// request that died, and may be taken over by a retry.
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primarykey"`
+	Scope        string `gorm:"size:64;not null;uniqueIndex:idx_idempotency_scope_key"`
+	Key          string `gorm:"column:idempotency_key;size:255;not null;uniqueIndex:idx_idempotency_scope_key"`
+	RequestHash  string `gorm:"size:64;not null"`
+	StatusCode   int    `gorm:"not null;default:0"`
+	ContentType  string `gorm:"size:255"`
+	ResponseBody []byte
+	LockedUntil  time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+// Completed reports whether the original request finished and its response
+// was stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
@@ -4,12 +4,24 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// OrderStatuses lists every status an order can be in.
+var OrderStatuses = []string{OrderStatusPending, OrderStatusConfirmed, OrderStatusDelivered, OrderStatusCancelled}
+
 type Order struct {
 	gorm.Model
+	Versioned
 	ProductID int     `json:"product_id"`
 	Quantity  int     `json:"quantity"`
 	UserId    int     `json:"user_id"`
 	Total     float64 `json:"total"`
+	Status    string  `json:"status" gorm:"not null;default:pending;index"`
 }
 
 // NewOrder creates a new Order instance
@@ -19,5 +31,6 @@ func NewOrder(productID, quantity int, total float64, userId int) *Order {
 		Quantity:  quantity,
 		Total:     total,
 		UserId:    userId,
+		Status:    OrderStatusPending,
 	}
 }
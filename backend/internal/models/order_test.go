@@ -19,6 +19,7 @@ func TestNewOrder(t *testing.T) {
 	assert.Equal(t, userId, order.UserId)
 	assert.Equal(t, quantity, order.Quantity)
 	assert.Equal(t, total, order.Total)
+	assert.Equal(t, OrderStatusPending, order.Status)
 }
 
 func TestOrderFields(t *testing.T) {
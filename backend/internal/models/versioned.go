@@ -0,0 +1,16 @@
+package models
+
+// Versioned is embedded by models that use optimistic locking. Version starts
+// at 1 and is bumped by every update, so a client that read version N can ask
+// for its write to apply only if nobody else changed the row since.
+type Versioned struct {
+	Version uint `json:"version" gorm:"not null;default:1"`
+}
+
+func (v *Versioned) CurrentVersion() uint {
+	return v.Version
+}
+
+func (v *Versioned) SetVersion(version uint) {
+	v.Version = version
+}
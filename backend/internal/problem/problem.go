@@ -0,0 +1,115 @@
+// Package problem writes error responses as RFC 7807 problem details
+// (application/problem+json), the single error format of the API.
+package problem
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/dto"
+	"backend/internal/validation"
+	"backend/pkg/requestid"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// TypeBase prefixes every problem type URI. Each type is documented under its
+// own anchor of docs/problems.md.
+const TypeBase = "https://github.com/paulodhiambo/savannah/blob/main/docs/problems.md#"
+
+// Type is a kind of problem: a stable URI clients can switch on, a short
+// human-readable title and the HTTP status it is served with.
+type Type struct {
+	URI    string
+	Title  string
+	Status int
+}
+
+var (
+	BadRequest           = newType("bad-request", http.StatusBadRequest)
+	Unauthorized         = newType("unauthorized", http.StatusUnauthorized)
+	Forbidden            = newType("forbidden", http.StatusForbidden)
+	NotFound             = newType("not-found", http.StatusNotFound)
+	Conflict             = newType("conflict", http.StatusConflict)
+	PreconditionFailed   = newType("precondition-failed", http.StatusPreconditionFailed)
+	RequestTooLarge      = newType("request-too-large", http.StatusRequestEntityTooLarge)
+	UnsupportedMediaType = newType("unsupported-media-type", http.StatusUnsupportedMediaType)
+	Validation           = newType("validation", http.StatusUnprocessableEntity)
+	FailedDependency     = newType("failed-dependency", http.StatusFailedDependency)
+	PreconditionRequired = newType("precondition-required", http.StatusPreconditionRequired)
+	TooManyRequests      = newType("too-many-requests", http.StatusTooManyRequests)
+	Internal             = newType("internal", http.StatusInternalServerError)
+	Unavailable          = newType("unavailable", http.StatusServiceUnavailable)
+)
+
+func newType(slug string, status int) Type {
+	return Type{URI: TypeBase + slug, Title: http.StatusText(status), Status: status}
+}
+
+// New describes a problem of type t with the request it occurred on.
+func New(c *gin.Context, t Type, detail string) *dto.Problem {
+	return &dto.Problem{
+		Type:      t.URI,
+		Title:     t.Title,
+		Status:    t.Status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: requestid.From(c.Request.Context()),
+	}
+}
+
+// unavailableDetail stands in for the cause of an unavailable dependency,
+// which names hosts and drivers.
+const unavailableDetail = "A dependency did not answer in time. Retry later."
+
+// FromError describes err by its apperrors kind, with the cause as detail but
+// not the operations it failed in. Validation errors about individual fields
+// list them in the errors member. Unclassified errors are
+// reported as internal without detail, and unavailable ones with a generic
+// detail, so no implementation details leak; callers log err instead.
+func FromError(c *gin.Context, err error) *dto.Problem {
+	t := TypeOf(err)
+	switch t {
+	case Internal:
+		return New(c, t, "")
+	case Unavailable:
+		return New(c, t, unavailableDetail)
+	}
+	p := New(c, t, apperrors.Message(err))
+	if fields, ok := validation.Fields(err); ok && t == Validation {
+		p.Errors = fields
+	}
+	return p
+}
+
+// TypeOf returns the problem type matching the apperrors kind of err.
+func TypeOf(err error) Type {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		return NotFound
+	case errors.Is(err, apperrors.ErrConflict):
+		return Conflict
+	case errors.Is(err, apperrors.ErrValidation):
+		return Validation
+	case errors.Is(err, apperrors.ErrPreconditionFailed):
+		return PreconditionFailed
+	case errors.Is(err, apperrors.ErrUnavailable):
+		return Unavailable
+	default:
+		return Internal
+	}
+}
+
+// Respond writes p and aborts the remaining handlers.
+func Respond(c *gin.Context, p *dto.Problem) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Abort responds with a problem of type t.
+func Abort(c *gin.Context, t Type, detail string) {
+	Respond(c, New(c, t, detail))
+}
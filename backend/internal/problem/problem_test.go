@@ -0,0 +1,59 @@
+package problem
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/validation"
+	"backend/pkg/requestid"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/orders?x=1", nil)
+	c.Request = c.Request.WithContext(requestid.With(c.Request.Context(), "req-1"))
+
+	fields := validation.Errors{{Field: "quantity", Code: "gt", Message: "quantity must be greater than 0"}}
+
+	p := FromError(c, apperrors.Validation("create order", fields))
+	assert.Equal(t, TypeBase+"validation", p.Type)
+	assert.Equal(t, "Unprocessable Entity", p.Title)
+	assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+	assert.Equal(t, "quantity must be greater than 0", p.Detail)
+	assert.Equal(t, "/api/v1/orders", p.Instance)
+	assert.Equal(t, "req-1", p.RequestID)
+	assert.Len(t, p.Errors, 1)
+
+	p = FromError(c, fmt.Errorf("update order: %w", apperrors.NotFound("get order by ID", errors.New("record not found"))))
+	assert.Equal(t, NotFound.URI, p.Type)
+	assert.Equal(t, "record not found", p.Detail)
+
+	p = FromError(c, apperrors.Conflict("repository.Create", errors.New("duplicated key not allowed")))
+	assert.Equal(t, Conflict.URI, p.Type)
+	assert.Equal(t, "duplicated key not allowed", p.Detail)
+
+	p = FromError(c, errors.New("pq: password authentication failed"))
+	assert.Equal(t, Internal.URI, p.Type)
+	assert.Empty(t, p.Detail)
+	assert.Empty(t, p.Errors)
+
+	p = FromError(c, apperrors.Unavailable("list orders", errors.New("dial tcp 10.0.3.7:5432: i/o timeout")))
+	assert.Equal(t, Unavailable.URI, p.Type)
+	assert.NotContains(t, p.Detail, "10.0.3.7")
+	assert.NotEmpty(t, p.Detail)
+}
+
+func TestTypeOf(t *testing.T) {
+	assert.Equal(t, NotFound, TypeOf(apperrors.NotFound("get order", errors.New("record not found"))))
+	assert.Equal(t, Conflict, TypeOf(apperrors.Conflict("create customer", errors.New("duplicate"))))
+	assert.Equal(t, PreconditionFailed, TypeOf(apperrors.PreconditionFailed("update order", errors.New("stale"))))
+	assert.Equal(t, Unavailable, TypeOf(apperrors.Unavailable("list orders", errors.New("timeout"))))
+	assert.Equal(t, Internal, TypeOf(errors.New("boom")))
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Cursor is a keyset position: the (created_at, id) of the row a page
+// starts after or ends before. It travels to clients as an opaque, signed
+// token so they cannot forge positions or change the sort order mid-walk.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	Desc      bool      `json:"d,omitempty"`
+	Before    bool      `json:"b,omitempty"`
+}
+
+// Keyset is a parsed keyset page request.
+type Keyset struct {
+	Limit  int
+	Desc   bool
+	Cursor *Cursor
+}
+
+// UsesCursor reports whether the request asked for keyset pagination. An
+// empty cursor parameter requests the first page.
+func UsesCursor(values url.Values) bool {
+	_, ok := values["cursor"]
+	return ok
+}
+
+// ParseKeyset turns cursor, limit, sort and the filters in spec into a
+// repository query ordered by (created_at, id). Only created_at may be used
+// as sort field; a cursor keeps the direction it was issued with. The query
+// fetches one row more than the limit so Paginate can tell whether another
+// page exists. An empty secret is refused, since anyone could sign cursors
+// with it.
+func ParseKeyset(values url.Values, spec Spec, secret []byte) (repositories.Query, Keyset, error) {
+	var q repositories.Query
+	if len(secret) == 0 {
+		return q, Keyset{}, errNoSecret
+	}
+
+	page, err := Pagination(values)
+	if err != nil {
+		return q, Keyset{}, err
+	}
+	keyset := Keyset{Limit: page.Limit}
+
+	switch values.Get("sort") {
+	case "", "created_at":
+	case "-created_at":
+		keyset.Desc = true
+	default:
+		return q, keyset, invalid("cursor pagination can only sort by created_at or -created_at")
+	}
+
+	if token := values.Get("cursor"); token != "" {
+		cursor, err := DecodeCursor(token, secret)
+		if err != nil {
+			return q, keyset, err
+		}
+		keyset.Cursor = &cursor
+		keyset.Desc = cursor.Desc
+	}
+
+	if q.Scopes, err = Filters(values, spec); err != nil {
+		return q, keyset, err
+	}
+
+	// Walking backwards reads the rows before the cursor in reverse order;
+	// Paginate flips them back.
+	reverse := keyset.Desc
+	if keyset.Cursor != nil && keyset.Cursor.Before {
+		reverse = !reverse
+	}
+	if keyset.Cursor != nil {
+		operator := ">"
+		if reverse {
+			operator = "<"
+		}
+		position := clause.Expr{
+			SQL:  "(?, ?) " + operator + " (?, ?)",
+			Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: "created_at"}, clause.Column{Table: clause.CurrentTable, Name: "id"}, keyset.Cursor.CreatedAt, keyset.Cursor.ID},
+		}
+		q.Scopes = append(q.Scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where(position)
+		})
+	}
+	q.Sort = []clause.OrderByColumn{
+		{Column: clause.Column{Table: clause.CurrentTable, Name: "created_at"}, Desc: reverse},
+		{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}, Desc: reverse},
+	}
+	q.Limit = keyset.Limit + 1
+
+	return q, keyset, nil
+}
+
+// Paginate trims rows fetched with a ParseKeyset query to the page size,
+// restores display order and returns the cursors for the neighbouring pages.
+// A cursor is empty when there is no page in that direction.
+func Paginate[T any](rows []T, keyset Keyset, key func(T) (time.Time, uint), secret []byte) (page []T, next, prev string) {
+	more := len(rows) > keyset.Limit
+	if more {
+		rows = rows[:keyset.Limit]
+	}
+
+	backwards := keyset.Cursor != nil && keyset.Cursor.Before
+	if backwards {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	position := func(row T, before bool) string {
+		createdAt, id := key(row)
+		return EncodeCursor(Cursor{CreatedAt: createdAt, ID: id, Desc: keyset.Desc, Before: before}, secret)
+	}
+	// Moving forward, the extra row proves there is a next page and having
+	// started from a cursor proves there is a previous one; walking
+	// backwards swaps the two.
+	if (!backwards && more) || backwards {
+		next = position(rows[len(rows)-1], false)
+	}
+	if (backwards && more) || (!backwards && keyset.Cursor != nil) {
+		prev = position(rows[0], true)
+	}
+	return rows, next, prev
+}
+
+// EncodeCursor serialises and signs c.
+func EncodeCursor(c Cursor, secret []byte) string {
+	payload, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sign(payload, secret))
+}
+
+// DecodeCursor verifies and parses a token produced by EncodeCursor.
+func DecodeCursor(token string, secret []byte) (Cursor, error) {
+	var c Cursor
+	if len(secret) == 0 {
+		return c, errNoSecret
+	}
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return c, invalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return c, invalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, sign(payload, secret)) {
+		return c, invalidCursor
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return c, invalidCursor
+	}
+	return c, nil
+}
+
+// errNoSecret is an internal error: config always provides a cursor secret.
+var errNoSecret = errors.New("cursor secret is empty")
+
+var invalidCursor = invalid("cursor is malformed or was not issued by this server")
+
+func sign(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/database"
+	"backend/pkg/logging"
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 6, 14, 12, 0, 0, 123456000, time.UTC), ID: 42, Desc: true}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor, testSecret), testSecret)
+	assert.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+	assert.True(t, decoded.Desc)
+}
+
+func TestCursor_RejectsForgery(t *testing.T) {
+	token := EncodeCursor(Cursor{CreatedAt: time.Now(), ID: 1}, testSecret)
+	forged := EncodeCursor(Cursor{CreatedAt: time.Now(), ID: 1}, []byte("other-secret"))
+
+	for _, bad := range []string{"", "garbage", token + "x", forged, "e30." + token[len(token)-43:]} {
+		_, err := DecodeCursor(bad, testSecret)
+		assert.ErrorIs(t, err, apperrors.ErrValidation, bad)
+	}
+}
+
+func TestCursor_RequiresSecret(t *testing.T) {
+	emptyKeyToken := EncodeCursor(Cursor{CreatedAt: time.Now(), ID: 1}, nil)
+
+	_, err := DecodeCursor(emptyKeyToken, nil)
+	assert.Error(t, err)
+	_, _, err = ParseKeyset(url.Values{"cursor": {""}}, Spec{}, nil)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, apperrors.ErrValidation)
+}
+
+func TestParseKeyset_RejectsOtherSorts(t *testing.T) {
+	_, _, err := ParseKeyset(url.Values{"cursor": {""}, "sort": {"total"}}, Spec{}, testSecret)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
+
+func TestKeyset_Walk(t *testing.T) {
+	require.NoError(t, config.Load())
+	logger := logging.GetLogger()
+	cfg := config.AppConfig
+	cfg.DBDriver = database.DriverSQLite
+	cfg.DatabaseURL = filepath.Join(t.TempDir(), "keyset.db")
+	db, err := database.Open(cfg, logger)
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate(db, logger))
+
+	ctx := context.Background()
+	repo := repositories.NewOrderRepository(db, logger)
+	createdAt := time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		order := &models.Order{ProductID: i, Quantity: 1, UserId: 1}
+		// Two orders share a timestamp so the id tie-breaker is exercised.
+		order.CreatedAt = createdAt.Add(time.Duration(i/2) * time.Minute)
+		require.NoError(t, repo.Create(ctx, order))
+	}
+
+	key := func(o models.Order) (time.Time, uint) { return o.CreatedAt, o.ID }
+	fetch := func(values url.Values) ([]models.Order, string, string) {
+		q, keyset, err := ParseKeyset(values, Spec{}, testSecret)
+		require.NoError(t, err)
+		rows, err := repo.List(ctx, q)
+		require.NoError(t, err)
+		return Paginate(rows, keyset, key, testSecret)
+	}
+	ids := func(orders []models.Order) []uint {
+		var out []uint
+		for _, o := range orders {
+			out = append(out, o.ID)
+		}
+		return out
+	}
+
+	page, next, prev := fetch(url.Values{"cursor": {""}, "limit": {"2"}})
+	assert.Equal(t, []uint{1, 2}, ids(page))
+	assert.Empty(t, prev)
+
+	// An order arriving mid-walk must not shift or duplicate later pages.
+	late := &models.Order{ProductID: 6, Quantity: 1, UserId: 1}
+	late.CreatedAt = createdAt.Add(time.Hour)
+	require.NoError(t, repo.Create(ctx, late))
+
+	page, next, prev = fetch(url.Values{"cursor": {next}, "limit": {"2"}})
+	assert.Equal(t, []uint{3, 4}, ids(page))
+	assert.NotEmpty(t, prev)
+
+	page, next, _ = fetch(url.Values{"cursor": {next}, "limit": {"2"}})
+	assert.Equal(t, []uint{5, 6}, ids(page))
+	assert.Empty(t, next)
+
+	page, _, prev = fetch(url.Values{"cursor": {prev}, "limit": {"2"}})
+	assert.Equal(t, []uint{1, 2}, ids(page))
+	assert.Empty(t, prev)
+
+	page, next, _ = fetch(url.Values{"cursor": {""}, "limit": {"4"}, "sort": {"-created_at"}})
+	assert.Equal(t, []uint{6, 5, 4, 3}, ids(page))
+
+	page, _, _ = fetch(url.Values{"cursor": {next}, "limit": {"4"}})
+	assert.Equal(t, []uint{2, 1}, ids(page))
+}
@@ -0,0 +1,239 @@
+package query
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/repositories"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Operator compares a column against a filter value.
+type Operator int
+
+const (
+	Equals Operator = iota
+	AtLeast
+	AtMost
+)
+
+// Filter maps one query parameter onto a comparison against a whitelisted
+// column. Parse converts the raw parameter into the column's type and rejects
+// anything else. Multiple lets an Equals filter take comma separated values,
+// matched with IN.
+type Filter struct {
+	Column   string
+	Operator Operator
+	Parse    func(raw string) (interface{}, error)
+	Multiple bool
+}
+
+// Spec whitelists what a list endpoint may sort and filter on. Parameters
+// that are not listed are ignored, so the raw input never reaches SQL.
+type Spec struct {
+	Sortable    []string
+	Filters     map[string]Filter
+	DefaultSort string
+}
+
+// Trash returns a copy of s for browsing soft-deleted rows: it can also sort
+// on deleted_at, filter with deleted_after and deleted_before, and lists the
+// most recently deleted rows first by default.
+func (s Spec) Trash() Spec {
+	trash := Spec{
+		Sortable:    append(append([]string(nil), s.Sortable...), "deleted_at"),
+		Filters:     map[string]Filter{},
+		DefaultSort: "-deleted_at",
+	}
+	for param, filter := range s.Filters {
+		trash.Filters[param] = filter
+	}
+	trash.Filters["deleted_after"] = Filter{Column: "deleted_at", Operator: AtLeast, Parse: Time}
+	trash.Filters["deleted_before"] = Filter{Column: "deleted_at", Operator: AtMost, Parse: Time}
+	return trash
+}
+
+// Page is the page requested through the page and limit parameters.
+type Page struct {
+	Number int
+	Limit  int
+}
+
+// Parse turns page, limit, sort and the filters declared in spec into a
+// repository query. Invalid input fails with apperrors.ErrValidation.
+func Parse(values url.Values, spec Spec) (repositories.Query, Page, error) {
+	var q repositories.Query
+
+	page, err := Pagination(values)
+	if err != nil {
+		return q, page, err
+	}
+	q.Limit = page.Limit
+	q.Offset = (page.Number - 1) * page.Limit
+
+	if q.Sort, err = Sort(values.Get("sort"), spec); err != nil {
+		return q, page, err
+	}
+
+	if q.Scopes, err = Filters(values, spec); err != nil {
+		return q, page, err
+	}
+
+	return q, page, nil
+}
+
+// Filters returns one scope per filter in spec that appears in values.
+func Filters(values url.Values, spec Spec) ([]repositories.Scope, error) {
+	var scopes []repositories.Scope
+	for param, filter := range spec.Filters {
+		raw, ok := values[param]
+		if !ok {
+			continue
+		}
+		scope, err := filter.scope(param, raw)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Pagination reads the page and limit parameters, applying the defaults and
+// the upper bound on limit.
+func Pagination(values url.Values) (Page, error) {
+	page := Page{Number: 1, Limit: DefaultLimit}
+	var err error
+	if raw := values.Get("page"); raw != "" {
+		if page.Number, err = strconv.Atoi(raw); err != nil || page.Number < 1 {
+			return page, invalid("page must be a positive integer")
+		}
+	}
+	if raw := values.Get("limit"); raw != "" {
+		if page.Limit, err = strconv.Atoi(raw); err != nil || page.Limit < 1 || page.Limit > MaxLimit {
+			return page, invalid(fmt.Sprintf("limit must be between 1 and %d", MaxLimit))
+		}
+	}
+	return page, nil
+}
+
+// Sort parses a comma separated list of sortable columns, each optionally
+// prefixed with "-" for descending order. The primary key is appended as a
+// tie-breaker so pages stay stable.
+func Sort(raw string, spec Spec) ([]clause.OrderByColumn, error) {
+	if raw == "" {
+		raw = spec.DefaultSort
+	}
+
+	var columns []clause.OrderByColumn
+	hasID := false
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		desc := strings.HasPrefix(field, "-")
+		name := strings.TrimPrefix(field, "-")
+		if !contains(spec.Sortable, name) {
+			return nil, invalid(fmt.Sprintf("cannot sort by %q", name))
+		}
+		hasID = hasID || name == "id"
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: name}, Desc: desc})
+	}
+	if !hasID {
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+	}
+	return columns, nil
+}
+
+func (f Filter) scope(param string, raw []string) (repositories.Scope, error) {
+	var values []interface{}
+	for _, item := range raw {
+		parts := []string{item}
+		if f.Multiple {
+			parts = strings.Split(item, ",")
+		}
+		for _, part := range parts {
+			value, err := f.Parse(strings.TrimSpace(part))
+			if err != nil {
+				return nil, invalid(fmt.Sprintf("invalid %s: %v", param, err))
+			}
+			values = append(values, value)
+		}
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: f.Column}
+	var expr clause.Expression
+	switch {
+	case f.Operator == AtLeast:
+		expr = clause.Gte{Column: column, Value: values[0]}
+	case f.Operator == AtMost:
+		expr = clause.Lte{Column: column, Value: values[0]}
+	case len(values) == 1:
+		expr = clause.Eq{Column: column, Value: values[0]}
+	default:
+		expr = clause.IN{Column: column, Values: values}
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(expr)
+	}, nil
+}
+
+// Int parses a filter value as a base 10 integer.
+func Int(raw string) (interface{}, error) {
+	return strconv.Atoi(raw)
+}
+
+// Time parses a filter value as an RFC 3339 timestamp or a plain date.
+func Time(raw string) (interface{}, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	return t, nil
+}
+
+// OneOf accepts only the listed values.
+func OneOf(allowed ...string) func(string) (interface{}, error) {
+	return func(raw string) (interface{}, error) {
+		if !contains(allowed, raw) {
+			return nil, fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+		}
+		return raw, nil
+	}
+}
+
+// String accepts any non-empty value.
+func String(raw string) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("value must not be empty")
+	}
+	return raw, nil
+}
+
+func invalid(message string) error {
+	return apperrors.Validation("parse query", errors.New(message))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"backend/internal/apperrors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var testSpec = Spec{
+	Sortable:    []string{"id", "created_at", "status"},
+	DefaultSort: "id",
+	Filters: map[string]Filter{
+		"status":        {Column: "status", Parse: OneOf("pending", "delivered"), Multiple: true},
+		"product_id":    {Column: "product_id", Parse: Int},
+		"created_after": {Column: "created_at", Operator: AtLeast, Parse: Time},
+	},
+}
+
+type order struct {
+	ID     uint
+	Status string
+}
+
+func toSQL(t *testing.T, values url.Values) string {
+	t.Helper()
+	q, _, err := Parse(values, testSpec)
+	assert.NoError(t, err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		for _, scope := range q.Scopes {
+			tx = tx.Scopes(scope)
+		}
+		for _, column := range q.Sort {
+			tx = tx.Order(column)
+		}
+		return tx.Limit(q.Limit).Offset(q.Offset).Find(&[]order{})
+	})
+}
+
+func TestParse(t *testing.T) {
+	sql := toSQL(t, url.Values{
+		"page":          {"3"},
+		"limit":         {"10"},
+		"sort":          {"-created_at"},
+		"status":        {"pending,delivered"},
+		"product_id":    {"7"},
+		"created_after": {"2024-06-14"},
+	})
+
+	assert.Contains(t, sql, `"orders"."status" IN ('pending','delivered')`)
+	assert.Contains(t, sql, `"orders"."product_id" = 7`)
+	assert.Contains(t, sql, `"orders"."created_at" >= '2024-06-14 00:00:00'`)
+	assert.Contains(t, sql, `ORDER BY "created_at" DESC,"id" LIMIT 10 OFFSET 20`)
+}
+
+func TestParse_Defaults(t *testing.T) {
+	q, page, err := Parse(url.Values{}, testSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Number: 1, Limit: DefaultLimit}, page)
+	assert.Empty(t, q.Scopes)
+	assert.Equal(t, 0, q.Offset)
+	assert.Len(t, q.Sort, 1)
+	assert.Equal(t, "id", q.Sort[0].Column.Name)
+}
+
+func TestSpec_Trash(t *testing.T) {
+	trash := testSpec.Trash()
+
+	q, _, err := Parse(url.Values{"deleted_after": {"2024-06-14"}, "status": {"pending"}}, trash)
+	assert.NoError(t, err)
+	assert.Len(t, q.Scopes, 2)
+	assert.Equal(t, "deleted_at", q.Sort[0].Column.Name)
+	assert.True(t, q.Sort[0].Desc)
+
+	_, _, err = Parse(url.Values{"sort": {"deleted_at"}}, testSpec)
+	assert.ErrorIs(t, err, apperrors.ErrValidation, "the original spec is left untouched")
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+	}{
+		{name: "Unknown sort field", values: url.Values{"sort": {"password"}}},
+		{name: "Injected sort", values: url.Values{"sort": {"id;DROP TABLE orders"}}},
+		{name: "Status outside enum", values: url.Values{"status": {"lost"}}},
+		{name: "Non-numeric filter", values: url.Values{"product_id": {"abc"}}},
+		{name: "Bad date", values: url.Values{"created_after": {"yesterday"}}},
+		{name: "Zero page", values: url.Values{"page": {"0"}}},
+		{name: "Limit too large", values: url.Values{"limit": {"1000"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Parse(tt.values, testSpec)
+			assert.ErrorIs(t, err, apperrors.ErrValidation)
+		})
+	}
+}
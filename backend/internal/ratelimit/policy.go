@@ -0,0 +1,105 @@
+// Package ratelimit holds the token-bucket rate limits applied per client
+// and route, and the stores that keep the buckets.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPolicy names the policy of routes without one of their own.
+const DefaultPolicy = "default"
+
+// Policy allows Limit requests per Period. Buckets start full, so a client
+// may burst up to Limit requests and then gets one more every Period/Limit.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Rate is the number of tokens the bucket regains per second.
+func (p Policy) Rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// String formats p as a RateLimit-Policy header value, e.g. 60;w=60.
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(p.Period.Seconds()))
+}
+
+// Policies maps "METHOD /route/template" to the policy of that route.
+// Routes not listed fall back to Default; a nil Default leaves them
+// unlimited.
+type Policies struct {
+	Default *Policy
+	Routes  map[string]Policy
+}
+
+// For returns the name and policy that apply to the route, or false when the
+// route is unlimited. Requests of routes sharing the default policy share one
+// bucket per client.
+func (p Policies) For(method, route string) (string, Policy, bool) {
+	name := method + " " + route
+	if policy, ok := p.Routes[name]; ok {
+		return name, policy, true
+	}
+	if p.Default != nil {
+		return DefaultPolicy, *p.Default, true
+	}
+	return "", Policy{}, false
+}
+
+// ParsePolicies reads the default policy and a comma separated list of route
+// policies, e.g. "POST /api/v1/orders=30/m, GET /api/v1/orders=120/m". An
+// empty or "off" default disables the default limit.
+func ParsePolicies(defaultSpec, routesSpec string) (Policies, error) {
+	policies := Policies{Routes: make(map[string]Policy)}
+	if spec := strings.TrimSpace(defaultSpec); spec != "" && spec != "off" {
+		policy, err := ParsePolicy(spec)
+		if err != nil {
+			return Policies{}, err
+		}
+		policies.Default = &policy
+	}
+
+	for _, entry := range strings.Split(routesSpec, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+			return Policies{}, fmt.Errorf("invalid route rate limit %q, want METHOD /path=N/period", entry)
+		}
+		policy, err := ParsePolicy(spec)
+		if err != nil {
+			return Policies{}, err
+		}
+		policies.Routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = policy
+	}
+	return policies, nil
+}
+
+// ParsePolicy reads a limit such as "60/m": a number of requests, a slash and
+// a period, either s, m or h or a duration such as 10s.
+func ParsePolicy(spec string) (Policy, error) {
+	count, period, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("invalid rate limit %q, want N/period", spec)
+	}
+	limit, err := strconv.Atoi(count)
+	if err != nil || limit <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: the count must be a positive number", spec)
+	}
+	switch period {
+	case "s", "m", "h":
+		period = "1" + period
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration < time.Second {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: the period must be at least 1s", spec)
+	}
+	return Policy{Limit: limit, Period: duration}, nil
+}
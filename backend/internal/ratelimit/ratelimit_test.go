@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    Policy
+		expectError bool
+	}{
+		{spec: "60/m", expected: Policy{Limit: 60, Period: time.Minute}},
+		{spec: " 5/s ", expected: Policy{Limit: 5, Period: time.Second}},
+		{spec: "1000/h", expected: Policy{Limit: 1000, Period: time.Hour}},
+		{spec: "10/30s", expected: Policy{Limit: 10, Period: 30 * time.Second}},
+		{spec: "60", expectError: true},
+		{spec: "0/m", expectError: true},
+		{spec: "ten/m", expectError: true},
+		{spec: "10/fortnight", expectError: true},
+		{spec: "10/100ms", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			policy, err := ParsePolicy(tt.spec)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("600/m", "post /api/v1/orders=30/m, GET /api/v1/orders/:id=120/m")
+	assert.NoError(t, err)
+
+	name, policy, ok := policies.For("POST", "/api/v1/orders")
+	assert.True(t, ok)
+	assert.Equal(t, "POST /api/v1/orders", name)
+	assert.Equal(t, Policy{Limit: 30, Period: time.Minute}, policy)
+
+	name, policy, ok = policies.For("GET", "/api/v1/customers")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultPolicy, name)
+	assert.Equal(t, Policy{Limit: 600, Period: time.Minute}, policy)
+
+	policies, err = ParsePolicies("off", "")
+	assert.NoError(t, err)
+	_, _, ok = policies.For("GET", "/api/v1/customers")
+	assert.False(t, ok)
+
+	_, err = ParsePolicies("600/m", "/api/v1/orders=30/m")
+	assert.Error(t, err)
+	_, err = ParsePolicies("600/m", "POST /api/v1/orders")
+	assert.Error(t, err)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	policy := Policy{Limit: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	first, _ := store.Take(ctx, "a", policy)
+	second, _ := store.Take(ctx, "a", policy)
+	third, _ := store.Take(ctx, "a", policy)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+	assert.Equal(t, time.Minute, second.Reset)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, 30*time.Second, third.RetryAfter)
+
+	// Other clients have their own bucket.
+	other, _ := store.Take(ctx, "b", policy)
+	assert.True(t, other.Allowed)
+
+	// One token comes back every Period/Limit.
+	now = now.Add(30 * time.Second)
+	refilled, _ := store.Take(ctx, "a", policy)
+	assert.True(t, refilled.Allowed)
+
+	// Refilled buckets are forgotten.
+	now = now.Add(sweepInterval)
+	_, _ = store.Take(ctx, "c", policy)
+	assert.NotContains(t, store.buckets, "a")
+	assert.NotContains(t, store.buckets, "b")
+}
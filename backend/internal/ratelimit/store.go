@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of taking a token from a bucket.
+type Decision struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left after this request.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token, when not Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. Implementations must be safe for
+// concurrent use; a shared store such as Redis lets several instances
+// enforce one limit.
+type Store interface {
+	// Take removes a token from the bucket at key, which follows policy.
+	Take(ctx context.Context, key string, policy Policy) (Decision, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	policy  Policy
+}
+
+// MemoryStore keeps buckets in process memory, so each instance enforces
+// its own limits. Full buckets are forgotten.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// sweepInterval is how often MemoryStore drops buckets that have refilled.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now, lastSweep: time.Now()}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, policy Policy) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.policy != policy {
+		b = &bucket{tokens: float64(policy.Limit), updated: now, policy: policy}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+
+	var decision Decision
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.tokens) / policy.Rate())
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = seconds((float64(policy.Limit) - b.tokens) / policy.Rate())
+	return decision, nil
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	b.tokens = math.Min(float64(b.policy.Limit), b.tokens+elapsed*b.policy.Rate())
+	b.updated = now
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.refill(now); b.tokens >= float64(b.policy.Limit) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
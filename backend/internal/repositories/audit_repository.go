@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/models"
+	"backend/pkg/authentication"
+	"backend/pkg/requestid"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// systemActor is recorded for changes made outside an authenticated
+// request, such as background jobs.
+const systemActor = "system"
+
+// unaudited lists JSON fields left out of audit diffs because every write
+// changes them.
+var unaudited = map[string]bool{"UpdatedAt": true, "version": true}
+
+type AuditRepository struct {
+	repo *GormRepository[models.AuditLog]
+}
+
+// AuditRepositoryImpl reads the audit log. Entries are only ever written by
+// the other repositories, alongside the change they describe.
+type AuditRepositoryImpl interface {
+	List(ctx context.Context, query Query) ([]models.AuditLog, error)
+	Count(ctx context.Context, query Query) (int64, error)
+}
+
+func NewAuditRepository(db *gorm.DB, logger *logrus.Logger) AuditRepositoryImpl {
+	return &AuditRepository{repo: NewGormRepository[models.AuditLog](db, logger, "audit log")}
+}
+
+func (r *AuditRepository) List(ctx context.Context, query Query) ([]models.AuditLog, error) {
+	return r.repo.List(ctx, query)
+}
+
+func (r *AuditRepository) Count(ctx context.Context, query Query) (int64, error) {
+	return r.repo.Count(ctx, query)
+}
+
+// ForEntity limits a query to the audit entries of one row.
+func ForEntity(entity string, id int) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("entity = ? AND entity_id = ?", entity, id)
+	}
+}
+
+// OfEntity limits a query to the audit entries of one entity type.
+func OfEntity(entity string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("entity = ?", entity)
+	}
+}
+
+// AfterID limits a query to entries recorded after the entry with id.
+func AfterID(id uint) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id > ?", id)
+	}
+}
+
+// RecordedBefore limits a query to entries written before t.
+func RecordedBefore(t time.Time) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_at < ?", t)
+	}
+}
+
+// ForOrdersOf limits a query to entries about orders placed by userID,
+// including orders that are in the trash. Purged orders no longer exist, so
+// their entries are not matched.
+func ForOrdersOf(userID int) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("entity_id IN (SELECT id FROM orders WHERE user_id = ?)", userID)
+	}
+}
+
+// audited runs change in a transaction and appends an audit entry for the row
+// it changed. change returns the row as it was before and after; nil stands
+// for a row that did not exist yet or no longer exists.
+func (r *GormRepository[T]) audited(ctx context.Context, op, action string, change func(tx *gorm.DB) (before, after *T, err error)) error {
+	return r.transaction(ctx, op, func(tx *gorm.DB) error {
+		before, after, err := change(tx)
+		if err != nil {
+			return err
+		}
+		return r.record(ctx, tx, action, before, after)
+	})
+}
+
+// transaction runs fn in a transaction, or a savepoint when r is already
+// bound to one. Errors fn did not classify itself are wrapped for op.
+func (r *GormRepository[T]) transaction(ctx context.Context, op string, fn func(tx *gorm.DB) error) error {
+	err := r.DB.WithContext(ctx).Transaction(fn)
+	var appErr *apperrors.Error
+	if err != nil && !errors.As(err, &appErr) {
+		return r.fail(op, err)
+	}
+	return err
+}
+
+func (r *GormRepository[T]) record(ctx context.Context, tx *gorm.DB, action string, before, after *T) error {
+	entry, err := r.auditEntry(ctx, tx, action, before, after)
+	if err == nil {
+		err = tx.Create(entry).Error
+	}
+	if err != nil {
+		return r.fail("record audit log", err)
+	}
+	return nil
+}
+
+func (r *GormRepository[T]) auditEntry(ctx context.Context, tx *gorm.DB, action string, before, after *T) (*models.AuditLog, error) {
+	row := after
+	if row == nil {
+		row = before
+	}
+	id, err := primaryKey(tx, row)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuditLog{
+		Entity:    r.name,
+		EntityID:  id,
+		Action:    action,
+		Actor:     actor(ctx),
+		RequestID: requestid.From(ctx),
+		Changes:   changes,
+	}, nil
+}
+
+// actor names the principal a change is attributed to.
+func actor(ctx context.Context) string {
+	principal := authentication.PrincipalFrom(ctx)
+	switch {
+	case principal == nil:
+		return systemActor
+	case principal.Login != "":
+		return principal.Login
+	default:
+		return principal.UserID
+	}
+}
+
+// diff compares the JSON encoding of two versions of a row field by field.
+// A nil side contributes no values, so creates and deletes list every field.
+func diff(before, after interface{}) (map[string]models.FieldChange, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.FieldChange{}
+	for name, value := range old {
+		if newValue, ok := updated[name]; !ok || string(newValue) != string(value) {
+			changes[name] = models.FieldChange{From: value, To: newValue}
+		}
+	}
+	for name, value := range updated {
+		if _, ok := old[name]; !ok {
+			changes[name] = models.FieldChange{To: value}
+		}
+	}
+	return changes, nil
+}
+
+func fields(row interface{}) (map[string]json.RawMessage, error) {
+	if reflect.ValueOf(row).IsNil() {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &values); err != nil {
+		return nil, err
+	}
+	for name := range unaudited {
+		delete(values, name)
+	}
+	return values, nil
+}
+
+// primaryKey reads the primary key of a gorm model.
+func primaryKey(db *gorm.DB, row interface{}) (uint, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(row); err != nil {
+		return 0, err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return 0, errors.New("model has no primary key")
+	}
+	value, _ := field.ValueOf(db.Statement.Context, reflect.ValueOf(row).Elem())
+	switch key := reflect.ValueOf(value); key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint(key.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(key.Uint()), nil
+	default:
+		return 0, errors.New("primary key is not an integer")
+	}
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"backend/pkg/authentication"
+	"backend/pkg/logging"
+	"backend/pkg/requestid"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestAuditRepository(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		logger := logging.GetLogger()
+		orders := NewOrderRepository(db, logger)
+		audit := NewAuditRepository(db, logger)
+
+		ctx := authentication.WithPrincipal(context.Background(), &authentication.Principal{UserID: "42", Login: "wanjiru"})
+		ctx = requestid.With(ctx, "req-1")
+
+		order := &models.Order{ProductID: 1, Quantity: 2, UserId: 1, Status: models.OrderStatusPending}
+		require.NoError(t, orders.Create(ctx, order))
+		order.Quantity = 3
+		require.NoError(t, orders.Update(ctx, order))
+		require.NoError(t, orders.Delete(ctx, int(order.ID)))
+		require.NoError(t, orders.Restore(ctx, int(order.ID)))
+		require.NoError(t, orders.Delete(ctx, int(order.ID)))
+		require.NoError(t, orders.Purge(context.Background(), int(order.ID)))
+
+		// A failed write leaves no trace.
+		stale := *order
+		stale.Version = 1
+		assert.Error(t, orders.Update(ctx, &stale))
+
+		entries, err := audit.List(ctx, Query{Sort: []clause.OrderByColumn{{Column: clause.Column{Name: "id"}}}}.Where(ForEntity("order", int(order.ID))))
+		require.NoError(t, err)
+		require.Len(t, entries, 6)
+
+		var actions []string
+		for _, entry := range entries {
+			actions = append(actions, entry.Action)
+		}
+		assert.Equal(t, []string{"create", "update", "delete", "restore", "delete", "purge"}, actions)
+
+		created, updated, purged := entries[0], entries[1], entries[5]
+		assert.Equal(t, "wanjiru", created.Actor)
+		assert.Equal(t, "req-1", created.RequestID)
+		assert.Equal(t, "order", created.Entity)
+		assert.Equal(t, order.ID, created.EntityID)
+		assert.JSONEq(t, `2`, string(created.Changes["quantity"].To))
+		assert.Nil(t, created.Changes["quantity"].From)
+
+		assert.Equal(t, map[string]models.FieldChange{"quantity": {From: []byte("2"), To: []byte("3")}}, updated.Changes)
+
+		assert.Equal(t, "system", purged.Actor)
+		assert.Empty(t, purged.RequestID)
+		var withoutRequest int64
+		require.NoError(t, db.Model(&models.AuditLog{}).Where("request_id IS NULL").Count(&withoutRequest).Error)
+		assert.Equal(t, int64(1), withoutRequest)
+		assert.JSONEq(t, `3`, string(purged.Changes["quantity"].From))
+
+		total, err := audit.Count(ctx, Query{}.Where(ForEntity("order", int(order.ID))))
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), total)
+	})
+}
+
+func TestAuditRepository_OrderEventScopes(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		logger := logging.GetLogger()
+		orders := NewOrderRepository(db, logger)
+		customers := NewCustomerRepository(db, logger)
+		audit := NewAuditRepository(db, logger)
+		ctx := context.Background()
+
+		mine := &models.Order{ProductID: 1, Quantity: 1, UserId: 7, Status: models.OrderStatusPending}
+		theirs := &models.Order{ProductID: 1, Quantity: 1, UserId: 8, Status: models.OrderStatusPending}
+		require.NoError(t, orders.Create(ctx, mine))
+		require.NoError(t, customers.Create(ctx, &models.Customer{Name: "Customer C1", Code: "C1"}))
+		require.NoError(t, orders.Create(ctx, theirs))
+		require.NoError(t, orders.Delete(ctx, int(mine.ID)))
+
+		byID := []clause.OrderByColumn{{Column: clause.Column{Name: "id"}}}
+		all, err := audit.List(ctx, Query{Sort: byID}.Where(OfEntity("order")))
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+
+		after, err := audit.List(ctx, Query{Sort: byID}.Where(OfEntity("order")).Where(AfterID(all[0].ID)))
+		require.NoError(t, err)
+		assert.Equal(t, []uint{theirs.ID, mine.ID}, []uint{after[0].EntityID, after[1].EntityID})
+
+		// Entries of trashed orders still belong to their owner.
+		own, err := audit.List(ctx, Query{Sort: byID}.Where(OfEntity("order")).Where(ForOrdersOf(7)))
+		require.NoError(t, err)
+		require.Len(t, own, 2)
+		assert.Equal(t, models.AuditCreate, own[0].Action)
+		assert.Equal(t, models.AuditDelete, own[1].Action)
+	})
+}
+
+func TestAuditRepository_PurgeDeleted(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		logger := logging.GetLogger()
+		customers := NewCustomerRepository(db, logger)
+		audit := NewAuditRepository(db, logger)
+		ctx := context.Background()
+
+		for _, code := range []string{"C1", "C2"} {
+			customer := &models.Customer{Name: "Customer " + code, Code: code}
+			require.NoError(t, customers.Create(ctx, customer))
+			require.NoError(t, customers.Delete(ctx, int(customer.ID)))
+		}
+
+		purged, err := customers.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+
+		total, err := audit.Count(ctx, Query{}.Where(func(db *gorm.DB) *gorm.DB {
+			return db.Where("entity = ? AND action = ?", "customer", models.AuditPurge)
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+	})
+}
@@ -1,93 +1,116 @@
 package repositories
 
 import (
-	"backend/internal/config"
+	"backend/internal/apperrors"
 	"backend/internal/models"
-	"backend/pkg/database"
 	"backend/pkg/logging"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestCustomerRepository_Create(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewCustomerRepository(db, logger)
-
-	customer := &models.Customer{Name: "John Doe", Code: "C123"}
-	err = repo.Create(customer)
-	assert.NoError(t, err)
-	assert.NotEqual(t, 0, customer.ID)
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewCustomerRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		customer := &models.Customer{Name: "John Doe", Code: "C123"}
+		err := repo.Create(ctx, customer)
+		assert.NoError(t, err)
+		assert.NotZero(t, customer.ID)
+	})
 }
 
 func TestCustomerRepository_Update(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewCustomerRepository(db, logger)
-
-	customer := &models.Customer{Name: "John Doe", Code: "C123"}
-	err = repo.Create(customer)
-	assert.NoError(t, err)
-
-	newName := "Jane Doe"
-	customer.Name = newName
-	err = repo.Update(customer)
-	assert.NoError(t, err)
-	updatedCustomer, err := repo.GetByID(customer.ID)
-	assert.NoError(t, err)
-	assert.Equal(t, newName, updatedCustomer.Name)
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewCustomerRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		customer := &models.Customer{Name: "John Doe", Code: "C123"}
+		err := repo.Create(ctx, customer)
+		assert.NoError(t, err)
+
+		newName := "Jane Doe"
+		customer.Name = newName
+		err = repo.Update(ctx, customer)
+		assert.NoError(t, err)
+		updatedCustomer, err := repo.GetByID(ctx, int(customer.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, newName, updatedCustomer.Name)
+	})
+}
+
+func TestCustomerRepository_List(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewCustomerRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		customers := []models.Customer{
+			{Name: "John Doe", Code: "C123"},
+			{Name: "Jane Doe", Code: "C124"},
+		}
+
+		for _, c := range customers {
+			err := repo.Create(ctx, &c)
+			assert.NoError(t, err)
+		}
+
+		allCustomers, err := repo.List(ctx, Query{})
+		assert.NoError(t, err)
+		assert.Len(t, allCustomers, len(customers))
+	})
 }
 
-func TestCustomerRepository_GetAll(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewCustomerRepository(db, logger)
-
-	customers := []models.Customer{
-		{Name: "John Doe", Code: "C123"},
-		{Name: "Jane Doe", Code: "C124"},
-	}
-
-	for _, c := range customers {
-		err := repo.Create(&c)
+func TestCustomerRepository_Delete(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewCustomerRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		customer := &models.Customer{Name: "John Doe", Code: "C123"}
+		err := repo.Create(ctx, customer)
+		assert.NoError(t, err)
+
+		err = repo.Delete(ctx, int(customer.ID))
 		assert.NoError(t, err)
-	}
 
-	allCustomers, err := repo.GetAll()
-	assert.NoError(t, err)
-	assert.Len(t, allCustomers, len(customers))
+		_, err = repo.GetByID(ctx, int(customer.ID))
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func TestCustomerRepository_UniqueCode(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewCustomerRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		customer := &models.Customer{Name: "John Doe", Code: "C123"}
+		assert.NoError(t, repo.Create(ctx, customer))
+
+		err := repo.Create(ctx, &models.Customer{Name: "Jane Doe", Code: "C123"})
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+		other := &models.Customer{Name: "Jane Doe", Code: "C124"}
+		assert.NoError(t, repo.Create(ctx, other))
+		other.Code = "C123"
+		assert.ErrorIs(t, repo.Update(ctx, other), apperrors.ErrConflict)
+	})
+}
+
+func TestCustomerRepository_CodeReusableAfterDelete(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewCustomerRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		customer := &models.Customer{Name: "John Doe", Code: "C123"}
+		assert.NoError(t, repo.Create(ctx, customer))
+		assert.NoError(t, repo.Delete(ctx, int(customer.ID)))
+
+		assert.NoError(t, repo.Create(ctx, &models.Customer{Name: "Jane Doe", Code: "C123"}))
+
+		// Restoring the old customer would give the code to two live rows.
+		err := repo.Restore(ctx, int(customer.ID))
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
 }
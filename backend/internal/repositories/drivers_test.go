@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"backend/internal/config"
+	"backend/pkg/database"
+	"backend/pkg/logging"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testDrivers lists the drivers the repository suite runs against. SQLite
+// always runs against a throwaway file; postgres runs when the configured
+// DB_* / DATABASE_URL settings point at a reachable server and is skipped
+// otherwise.
+var testDrivers = []string{database.DriverSQLite, database.DriverPostgres}
+
+// forEachDriver runs fn once per driver in testDrivers against freshly
+// migrated tables that are dropped again when the subtest finishes.
+func forEachDriver(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	for _, driver := range testDrivers {
+		t.Run(driver, func(t *testing.T) {
+			fn(t, openTestDB(t, driver))
+		})
+	}
+}
+
+func openTestDB(t *testing.T, driver string) *gorm.DB {
+	t.Helper()
+	if err := config.Load(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	logger := logging.GetLogger()
+
+	cfg := config.AppConfig
+	cfg.DBDriver = driver
+	switch driver {
+	case database.DriverSQLite:
+		cfg.DatabaseURL = filepath.Join(t.TempDir(), "test.db")
+	case database.DriverPostgres:
+		if config.AppConfig.DBDriver != database.DriverPostgres {
+			cfg.DatabaseURL = ""
+		}
+	}
+
+	db, err := database.Open(cfg, logger)
+	if err != nil {
+		t.Skipf("%s is not available: %v", driver, err)
+	}
+	if err := database.Migrate(db, logger); err != nil {
+		t.Fatalf("failed to migrate %s: %v", driver, err)
+	}
+	t.Cleanup(func() {
+		_ = database.DropTables(db, logger)
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"backend/internal/apperrors"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// wrapError classifies a gorm or driver error into one of the apperrors
+// kinds. Errors that match no kind are wrapped unchanged and surface as 500s.
+func wrapError(op string, err error) error {
+	var state interface{ SQLState() string }
+	var netErr net.Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NotFound(op, err)
+	case errors.Is(err, gorm.ErrDuplicatedKey), errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperrors.Conflict(op, err)
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return apperrors.Validation(op, err)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled),
+		errors.Is(err, driver.ErrBadConn), errors.As(err, &netErr):
+		return apperrors.Unavailable(op, err)
+	case errors.As(err, &state) && isUnavailableState(state.SQLState()):
+		return apperrors.Unavailable(op, err)
+	}
+	return &apperrors.Error{Op: op, Err: err}
+}
+
+// isUnavailableState reports Postgres SQLSTATEs for lost connections, server
+// shutdown and resource exhaustion.
+func isUnavailableState(code string) bool {
+	return strings.HasPrefix(code, "08") || strings.HasPrefix(code, "53") || strings.HasPrefix(code, "57P")
+}
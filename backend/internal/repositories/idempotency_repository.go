@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrLeaseLost reports that a request outlived its lease and a retry took
+// over its idempotency key, so its outcome must no longer be recorded.
+var ErrLeaseLost = errors.New("idempotency key was taken over by a retry")
+
+type IdempotencyRepository struct {
+	DB       *gorm.DB
+	logger   *logrus.Logger
+	timeouts Timeouts
+}
+
+type IdempotencyRepositoryImpl interface {
+	Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, record *models.IdempotencyKey) error
+	Release(ctx context.Context, record *models.IdempotencyKey) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+func NewIdempotencyRepository(db *gorm.DB, logger *logrus.Logger) IdempotencyRepositoryImpl {
+	return &IdempotencyRepository{DB: db, logger: logger, timeouts: DefaultTimeouts()}
+}
+
+// Reserve claims record's scope and key for a new request. When the key is
+// already taken by an unexpired record, nothing is written and that record is
+// returned instead, whether it is completed or still in flight. A reservation
+// for the same payload whose lease has run out is taken over instead, so keys
+// of requests that died with their process do not stay blocked until they
+// expire. The unique index on (scope, key) and the conditional takeover make
+// concurrent reservations race-free. The lease is stored as written in
+// record, which Complete and Release then match on.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	db := r.DB.WithContext(ctx)
+	// Databases keep microseconds at most; the lease must compare equal once
+	// read back.
+	record.LockedUntil = record.LockedUntil.Truncate(time.Microsecond)
+
+	expired := db.Where("scope = ? AND idempotency_key = ? AND expires_at <= ?", record.Scope, record.Key, time.Now())
+	if err := expired.Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, r.fail("clear expired idempotency key", err)
+	}
+
+	err := db.Create(record).Error
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, r.fail("reserve idempotency key", err)
+	}
+
+	var existing models.IdempotencyKey
+	if err := db.Where("scope = ? AND idempotency_key = ?", record.Scope, record.Key).First(&existing).Error; err != nil {
+		return nil, r.fail("get idempotency key", err)
+	}
+	if existing.Completed() || existing.RequestHash != record.RequestHash || existing.LockedUntil.After(time.Now()) {
+		return &existing, nil
+	}
+
+	// Only one retry may win the takeover: the update matches only while the
+	// row still holds the lease that was seen to have run out.
+	takeover := db.Model(&models.IdempotencyKey{}).
+		Where("id = ? AND status_code = 0", existing.ID).
+		Where("locked_until IS NULL OR locked_until = ?", existing.LockedUntil).
+		Updates(map[string]interface{}{"locked_until": record.LockedUntil, "expires_at": record.ExpiresAt})
+	if err := takeover.Error; err != nil {
+		return nil, r.fail("take over idempotency key", err)
+	}
+	if takeover.RowsAffected == 0 {
+		return &existing, nil
+	}
+	r.logger.Warnf("took over idempotency key %q after its lease expired", record.Key)
+	record.ID = existing.ID
+	record.CreatedAt = existing.CreatedAt
+	return nil, nil
+}
+
+// Complete stores the response of the request record was reserved for. It
+// fails with ErrLeaseLost when a retry has taken the key over meanwhile.
+func (r *IdempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyKey) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	result := r.leased(ctx, record).Updates(map[string]interface{}{
+		"status_code":   record.StatusCode,
+		"content_type":  record.ContentType,
+		"response_body": record.ResponseBody,
+	})
+	if err := result.Error; err != nil {
+		return r.fail("complete idempotency key", err)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.Conflict("complete idempotency key", ErrLeaseLost)
+	}
+	return nil
+}
+
+// Release drops a reservation so that a retry can run the request again. It
+// fails with ErrLeaseLost when a retry has taken the key over meanwhile.
+func (r *IdempotencyRepository) Release(ctx context.Context, record *models.IdempotencyKey) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	result := r.leased(ctx, record).Delete(&models.IdempotencyKey{})
+	if err := result.Error; err != nil {
+		return r.fail("release idempotency key", err)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.Conflict("release idempotency key", ErrLeaseLost)
+	}
+	return nil
+}
+
+// leased selects record's row for as long as it still holds record's lease.
+func (r *IdempotencyRepository) leased(ctx context.Context, record *models.IdempotencyKey) *gorm.DB {
+	return r.DB.WithContext(ctx).Model(&models.IdempotencyKey{}).Where("id = ? AND locked_until = ?", record.ID, record.LockedUntil)
+}
+
+// DeleteExpired removes every record whose replay window ended before now.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	result := r.DB.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if err := result.Error; err != nil {
+		return 0, r.fail("delete expired idempotency keys", err)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *IdempotencyRepository) fail(op string, err error) error {
+	r.logger.Warnf("failed to %s: %v", op, err)
+	return wrapError(op, err)
+}
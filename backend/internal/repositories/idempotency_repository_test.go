@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"backend/pkg/logging"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newIdempotencyKey(key string, ttl time.Duration) *models.IdempotencyKey {
+	return &models.IdempotencyKey{Scope: "scope", Key: key, RequestHash: "hash", LockedUntil: time.Now().Add(time.Minute), ExpiresAt: time.Now().Add(ttl)}
+}
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewIdempotencyRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		existing, err := repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+
+		existing, err = repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.False(t, existing.Completed())
+
+		// The same key in another scope is independent.
+		other := newIdempotencyKey("abc", time.Hour)
+		other.Scope = "other"
+		existing, err = repo.Reserve(ctx, other)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+}
+
+func TestIdempotencyRepository_TakesOverExpiredLease(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewIdempotencyRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		// The original request died without completing or releasing its key.
+		crashed := newIdempotencyKey("abc", time.Hour)
+		crashed.LockedUntil = time.Now().Add(-time.Second)
+		_, err := repo.Reserve(ctx, crashed)
+		require.NoError(t, err)
+
+		// A retry with another payload is still rejected.
+		changed := newIdempotencyKey("abc", time.Hour)
+		changed.RequestHash = "other"
+		existing, err := repo.Reserve(ctx, changed)
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+
+		retry := newIdempotencyKey("abc", time.Hour)
+		existing, err = repo.Reserve(ctx, retry)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+		assert.Equal(t, crashed.ID, retry.ID)
+
+		// The retry now holds a fresh lease, so a concurrent one waits.
+		existing, err = repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.False(t, existing.Completed())
+
+		retry.StatusCode = 201
+		require.NoError(t, repo.Complete(ctx, retry))
+		existing, err = repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.True(t, existing.Completed())
+	})
+}
+
+func TestIdempotencyRepository_LostLease(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewIdempotencyRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		// The original request is still running, but outlived its lease.
+		original := newIdempotencyKey("abc", time.Hour)
+		original.LockedUntil = time.Now().Add(-time.Second)
+		_, err := repo.Reserve(ctx, original)
+		require.NoError(t, err)
+		retry := newIdempotencyKey("abc", time.Hour)
+		_, err = repo.Reserve(ctx, retry)
+		require.NoError(t, err)
+		require.Equal(t, original.ID, retry.ID)
+
+		// When it finishes it neither stores its response nor frees the key.
+		original.StatusCode = 201
+		original.ResponseBody = []byte(`{"id":1}`)
+		assert.ErrorIs(t, repo.Complete(ctx, original), ErrLeaseLost)
+		assert.ErrorIs(t, repo.Release(ctx, original), ErrLeaseLost)
+
+		existing, err := repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.False(t, existing.Completed())
+
+		retry.StatusCode = 201
+		retry.ResponseBody = []byte(`{"id":2}`)
+		require.NoError(t, repo.Complete(ctx, retry))
+		existing, err = repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.Equal(t, `{"id":2}`, string(existing.ResponseBody))
+	})
+}
+
+func TestIdempotencyRepository_CompleteAndRelease(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewIdempotencyRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		record := newIdempotencyKey("abc", time.Hour)
+		_, err := repo.Reserve(ctx, record)
+		require.NoError(t, err)
+
+		record.StatusCode = 201
+		record.ContentType = "application/json"
+		record.ResponseBody = []byte(`{"id":1}`)
+		require.NoError(t, repo.Complete(ctx, record))
+
+		existing, err := repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.True(t, existing.Completed())
+		assert.Equal(t, `{"id":1}`, string(existing.ResponseBody))
+
+		require.NoError(t, repo.Release(ctx, existing))
+		existing, err = repo.Reserve(ctx, newIdempotencyKey("abc", time.Hour))
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+}
+
+func TestIdempotencyRepository_Expiry(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewIdempotencyRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		_, err := repo.Reserve(ctx, newIdempotencyKey("expired", -time.Minute))
+		require.NoError(t, err)
+		_, err = repo.Reserve(ctx, newIdempotencyKey("stale", -time.Minute))
+		require.NoError(t, err)
+
+		existing, err := repo.Reserve(ctx, newIdempotencyKey("expired", time.Hour))
+		require.NoError(t, err)
+		assert.Nil(t, existing, "an expired key can be reused")
+
+		deleted, err := repo.DeleteExpired(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
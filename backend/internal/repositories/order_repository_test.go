@@ -1,182 +1,305 @@
 package repositories
 
 import (
-	"backend/internal/config"
+	"backend/internal/apperrors"
 	"backend/internal/models"
-	"backend/pkg/database"
 	"backend/pkg/logging"
-	_ "fmt"
+	"context"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"testing"
+	"time"
 )
 
 func TestOrderRepository_Create(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewOrderRepository(db, logger)
-
-	order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
-	err = repo.Create(order)
-	assert.NoError(t, err)
-
-	assert.NotEqual(t, 0, order.ID)
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
+		err := repo.Create(ctx, order)
+		assert.NoError(t, err)
+
+		assert.NotEqual(t, 0, order.ID)
+	})
 }
 
 func TestOrderRepository_Update(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewOrderRepository(db, logger)
-
-	order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
-	err = repo.Create(order)
-	assert.NoError(t, err)
-
-	newQuantity := 3
-	order.Quantity = newQuantity
-	err = repo.Update(order)
-	assert.NoError(t, err)
-
-	updatedOrder, err := repo.GetByID(int(order.ID))
-	assert.NoError(t, err)
-	assert.Equal(t, newQuantity, updatedOrder.Quantity)
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
+		err := repo.Create(ctx, order)
+		assert.NoError(t, err)
+
+		newQuantity := 3
+		order.Quantity = newQuantity
+		err = repo.Update(ctx, order)
+		assert.NoError(t, err)
+
+		updatedOrder, err := repo.GetByID(ctx, int(order.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, newQuantity, updatedOrder.Quantity)
+	})
+}
+
+func TestOrderRepository_OptimisticLocking(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
+		assert.NoError(t, repo.Create(ctx, order))
+		assert.Equal(t, uint(1), order.Version)
+
+		first, _ := repo.GetByID(ctx, int(order.ID))
+		second, _ := repo.GetByID(ctx, int(order.ID))
+
+		first.Quantity = 5
+		assert.NoError(t, repo.Update(ctx, first))
+		assert.Equal(t, uint(2), first.Version)
+
+		second.Quantity = 7
+		err := repo.Update(ctx, second)
+		assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+		assert.Equal(t, uint(1), second.Version)
+
+		err = repo.DeleteIfVersion(ctx, int(order.ID), 1)
+		assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+
+		stored, err := repo.GetByID(ctx, int(order.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, 5, stored.Quantity)
+		assert.Equal(t, uint(2), stored.Version)
+
+		assert.NoError(t, repo.DeleteIfVersion(ctx, int(order.ID), 2))
+		err = repo.DeleteIfVersion(ctx, int(order.ID), 2)
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+		missing := &models.Order{}
+		missing.ID = 999
+		missing.Version = 1
+		assert.ErrorIs(t, repo.Update(ctx, missing), apperrors.ErrNotFound)
+	})
 }
 
 func TestOrderRepository_Delete(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewOrderRepository(db, logger)
-
-	order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
-	err = repo.Create(order)
-	assert.NoError(t, err)
-
-	err = repo.Delete(int(order.ID))
-	assert.NoError(t, err)
-
-	_, err = repo.GetByID(int(order.ID))
-	assert.Error(t, err) // Order should not exist
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
+		err := repo.Create(ctx, order)
+		assert.NoError(t, err)
+
+		err = repo.Delete(ctx, int(order.ID))
+		assert.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, int(order.ID))
+		assert.Error(t, err) // Order should not exist
+	})
 }
 
 func TestOrderRepository_GetByID(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewOrderRepository(db, logger)
-
-	order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
-	err = repo.Create(order)
-	assert.NoError(t, err)
-
-	fetchedOrder, err := repo.GetByID(int(order.ID))
-	assert.NoError(t, err)
-	assert.NotNil(t, fetchedOrder)
-	assert.Equal(t, order.ID, fetchedOrder.ID)
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1}
+		err := repo.Create(ctx, order)
+		assert.NoError(t, err)
+
+		fetchedOrder, err := repo.GetByID(ctx, int(order.ID))
+		assert.NoError(t, err)
+		assert.NotNil(t, fetchedOrder)
+		assert.Equal(t, order.ID, fetchedOrder.ID)
+	})
+}
+
+func TestOrderRepository_List(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		orders := []*models.Order{
+			{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1},
+			{ProductID: 2, Quantity: 3, Total: 59.97, UserId: 2},
+		}
+
+		for _, o := range orders {
+			err := repo.Create(ctx, o)
+			assert.NoError(t, err)
+		}
+
+		allOrders, err := repo.List(ctx, Query{})
+		assert.NoError(t, err)
+		assert.Len(t, allOrders, len(orders))
+
+		// Check if all order IDs match
+		for i, o := range allOrders {
+			assert.Equal(t, orders[i].ID, o.ID)
+		}
+	})
+}
+
+func TestOrderRepository_GetOrdersByUserID(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		// Create test data
+		orders := []models.Order{
+			{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0},
+			{ProductID: 2, Quantity: 1, UserId: 1, Total: 10.0},
+			{ProductID: 3, Quantity: 5, UserId: 2, Total: 50.0},
+		}
+
+		for _, order := range orders {
+			err := repo.Create(ctx, &order)
+			assert.NoError(t, err)
+		}
+
+		userOrders, err := repo.GetOrdersByUserID(ctx, 1, Query{})
+		assert.NoError(t, err)
+		assert.Len(t, userOrders, 2)
+	})
 }
 
-func TestOrderRepository_GetAll(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
+func TestOrderRepository_CanceledContext(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
 
-	_ = database.Connect(logger)
-	db := database.DB
+		_, err := repo.List(ctx, Query{})
+		assert.Error(t, err)
+
+		err = repo.Create(ctx, &models.Order{ProductID: 1, Quantity: 2, UserId: 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestOrderRepository_NotFound(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		_, err := repo.GetByID(ctx, 999)
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+		missing := &models.Order{ProductID: 1, Quantity: 2, UserId: 1}
+		missing.ID = 999
+		err = repo.Update(ctx, missing)
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+		err = repo.Delete(ctx, 999)
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
 
-	repo := NewOrderRepository(db, logger)
+func TestOrderRepository_ListQuery(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		for i := 1; i <= 5; i++ {
+			status := models.OrderStatusPending
+			if i%2 == 0 {
+				status = models.OrderStatusDelivered
+			}
+			err := repo.Create(ctx, &models.Order{ProductID: i, Quantity: i, UserId: 1, Status: status})
+			assert.NoError(t, err)
+		}
+
+		pending := Query{}.Where(func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", models.OrderStatusPending)
+		})
+		pending.Sort = []clause.OrderByColumn{{Column: clause.Column{Name: "quantity"}, Desc: true}}
+		pending.Limit = 2
+		pending.Offset = 1
+
+		orders, err := repo.List(ctx, pending)
+		assert.NoError(t, err)
+		assert.Len(t, orders, 2)
+		assert.Equal(t, 3, orders[0].Quantity)
+		assert.Equal(t, 1, orders[1].Quantity)
 
-	orders := []*models.Order{
-		{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1},
-		{ProductID: 2, Quantity: 3, Total: 59.97, UserId: 2},
-	}
+		total, err := repo.Count(ctx, pending)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
 
-	for _, o := range orders {
-		err := repo.Create(o)
+		userTotal, err := repo.CountOrdersByUserID(ctx, 1, Query{Limit: 1})
 		assert.NoError(t, err)
-	}
+		assert.Equal(t, int64(5), userTotal)
+	})
+}
+
+func TestOrderRepository_Patch(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
 
-	allOrders, err := repo.GetAll()
-	assert.NoError(t, err)
-	assert.Len(t, allOrders, len(orders))
+		order := &models.Order{ProductID: 1, Quantity: 2, Total: 39.98, UserId: 1, Status: models.OrderStatusPending}
+		assert.NoError(t, repo.Create(ctx, order))
 
-	// Check if all order IDs match
-	for i, o := range allOrders {
-		assert.Equal(t, orders[i].ID, o.ID)
-	}
+		order.Quantity = 4
+		order.Total = 0 // not listed, so it must survive
+		assert.NoError(t, repo.Patch(ctx, order, []string{"quantity"}))
+		assert.Equal(t, uint(2), order.Version)
+
+		stored, err := repo.GetByID(ctx, int(order.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, 4, stored.Quantity)
+		assert.Equal(t, 39.98, stored.Total)
+		assert.Equal(t, uint(2), stored.Version)
+		assert.Equal(t, order.CreatedAt.Unix(), stored.CreatedAt.Unix())
+
+		stale := *stored
+		stale.Version = 1
+		assert.ErrorIs(t, repo.Patch(ctx, &stale, []string{"quantity"}), apperrors.ErrPreconditionFailed)
+	})
 }
 
-func TestOrderRepository_GetOrdersByUserID(t *testing.T) {
-	err := config.Load()
-	if err != nil {
-		return
-	}
-	logger := logging.GetLogger()
-	defer func() {
-		_ = database.DropTables(logger)
-		_ = database.Close(logger)
-	}()
-
-	_ = database.Connect(logger)
-	db := database.DB
-
-	repo := NewOrderRepository(db, logger)
-
-	// Create test data
-	orders := []models.Order{
-		{ProductID: 1, Quantity: 2, UserId: 1, Total: 20.0},
-		{ProductID: 2, Quantity: 1, UserId: 1, Total: 10.0},
-		{ProductID: 3, Quantity: 5, UserId: 2, Total: 50.0},
-	}
-
-	for _, order := range orders {
-		err := repo.Create(&order)
-		assert.NoError(t, err)
-	}
+func TestOrderRepository_Trash(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewOrderRepository(db, logging.GetLogger())
+		ctx := context.Background()
+
+		kept := &models.Order{ProductID: 1, Quantity: 1, UserId: 1}
+		trashed := &models.Order{ProductID: 2, Quantity: 1, UserId: 1}
+		old := &models.Order{ProductID: 3, Quantity: 1, UserId: 1}
+		for _, order := range []*models.Order{kept, trashed, old} {
+			assert.NoError(t, repo.Create(ctx, order))
+		}
+		assert.NoError(t, repo.Delete(ctx, int(trashed.ID)))
+		assert.NoError(t, repo.Delete(ctx, int(old.ID)))
+		db.Unscoped().Model(old).Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+		inTrash, err := repo.List(ctx, Query{}.Where(Trashed))
+		assert.NoError(t, err)
+		assert.Len(t, inTrash, 2)
+		total, err := repo.Count(ctx, Query{}.Where(Trashed))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+
+		assert.ErrorIs(t, repo.Restore(ctx, int(kept.ID)), apperrors.ErrNotFound)
+		assert.NoError(t, repo.Restore(ctx, int(trashed.ID)))
+		restored, err := repo.GetByID(ctx, int(trashed.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), restored.Version)
+
+		purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-24*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		assert.ErrorIs(t, repo.Purge(ctx, int(kept.ID)), apperrors.ErrNotFound)
+		assert.NoError(t, repo.Delete(ctx, int(kept.ID)))
+		assert.NoError(t, repo.Purge(ctx, int(kept.ID)))
+
+		var remaining int64
+		db.Unscoped().Model(&models.Order{}).Count(&remaining)
+		assert.Equal(t, int64(1), remaining)
+	})
 }
@@ -0,0 +1,329 @@
+package repositories
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/models"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Repository is the CRUD surface shared by every entity repository.
+type Repository[T any] interface {
+	Create(ctx context.Context, entity *T) error
+	Update(ctx context.Context, entity *T) error
+	Patch(ctx context.Context, entity *T, columns []string) error
+	Delete(ctx context.Context, id int) error
+	DeleteIfVersion(ctx context.Context, id int, version uint) error
+	GetByID(ctx context.Context, id int) (*T, error)
+	List(ctx context.Context, query Query) ([]T, error)
+	Count(ctx context.Context, query Query) (int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// versioned is implemented by models embedding models.Versioned.
+type versioned interface {
+	CurrentVersion() uint
+	SetVersion(version uint)
+}
+
+// Scope narrows or orders a query, in the form accepted by gorm's Scopes.
+type Scope func(db *gorm.DB) *gorm.DB
+
+// Trashed limits a query to soft-deleted rows, so List and Count can browse
+// the trash.
+func Trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}, Value: nil})
+}
+
+// Query narrows, orders and pages a List call. The zero value lists every
+// row. Count only applies the Scopes.
+type Query struct {
+	Scopes []Scope
+	Sort   []clause.OrderByColumn
+	Limit  int
+	Offset int
+}
+
+// Where returns a copy of q with scope added to its filters.
+func (q Query) Where(scope Scope) Query {
+	q.Scopes = append(append([]Scope(nil), q.Scopes...), scope)
+	return q
+}
+
+func (q Query) filter(db *gorm.DB) *gorm.DB {
+	for _, scope := range q.Scopes {
+		db = db.Scopes(scope)
+	}
+	return db
+}
+
+func (q Query) apply(db *gorm.DB) *gorm.DB {
+	db = q.filter(db)
+	for _, column := range q.Sort {
+		db = db.Order(column)
+	}
+	if q.Limit > 0 {
+		db = db.Limit(q.Limit)
+	}
+	if q.Offset > 0 {
+		db = db.Offset(q.Offset)
+	}
+	return db
+}
+
+// GormRepository implements Repository for any gorm model. Entity
+// repositories embed it and add their own queries on top.
+type GormRepository[T any] struct {
+	DB       *gorm.DB
+	logger   *logrus.Logger
+	timeouts Timeouts
+	name     string
+}
+
+// NewGormRepository returns a repository for T. name is the singular entity
+// name used in log lines and error messages, e.g. "order".
+func NewGormRepository[T any](db *gorm.DB, logger *logrus.Logger, name string) *GormRepository[T] {
+	return &GormRepository[T]{DB: db, logger: logger, timeouts: DefaultTimeouts(), name: name}
+}
+
+func (r *GormRepository[T]) Create(ctx context.Context, entity *T) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	op := "create " + r.name
+	if v, ok := any(entity).(versioned); ok && v.CurrentVersion() == 0 {
+		v.SetVersion(1)
+	}
+	return r.audited(ctx, op, models.AuditCreate, func(tx *gorm.DB) (*T, *T, error) {
+		if err := tx.Create(entity).Error; err != nil {
+			return nil, nil, r.fail(op, err)
+		}
+		return nil, entity, nil
+	})
+}
+
+// Update writes every column of entity except created_at and deleted_at,
+// including zero values. It fails with apperrors.ErrNotFound when no live
+// row has the entity's primary key. Versioned entities are only written when
+// the stored version still equals entity's; otherwise the update fails with
+// apperrors.ErrPreconditionFailed. On success their version is bumped.
+func (r *GormRepository[T]) Update(ctx context.Context, entity *T) error {
+	return r.write(ctx, "update "+r.name, entity, func(db *gorm.DB) *gorm.DB {
+		return db.Select("*").Omit("created_at", "deleted_at")
+	})
+}
+
+// Patch is Update restricted to the given columns; updated_at and, for
+// versioned entities, version are written as well.
+func (r *GormRepository[T]) Patch(ctx context.Context, entity *T, columns []string) error {
+	if _, ok := any(entity).(versioned); ok {
+		columns = append(columns[:len(columns):len(columns)], "version")
+	}
+	return r.write(ctx, "patch "+r.name, entity, func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	})
+}
+
+func (r *GormRepository[T]) write(ctx context.Context, op string, entity *T, columns func(db *gorm.DB) *gorm.DB) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	return r.audited(ctx, op, models.AuditUpdate, func(tx *gorm.DB) (*T, *T, error) {
+		// Copying entity carries its primary key into the lookup.
+		before := new(T)
+		*before = *entity
+		if err := tx.First(before).Error; err != nil {
+			return nil, nil, r.fail(op, err)
+		}
+
+		db := tx.Model(entity)
+		v, isVersioned := any(entity).(versioned)
+		var expected uint
+		if isVersioned {
+			expected = v.CurrentVersion()
+			db = db.Where("version = ?", expected)
+			v.SetVersion(expected + 1)
+		}
+
+		result := columns(db).Updates(entity)
+		if result.Error == nil && result.RowsAffected > 0 {
+			return before, entity, nil
+		}
+		if isVersioned {
+			v.SetVersion(expected)
+		}
+		switch {
+		case result.Error != nil:
+			return nil, nil, r.fail(op, result.Error)
+		case isVersioned:
+			return nil, nil, r.stale(op)
+		default:
+			return nil, nil, apperrors.NotFound(op, gorm.ErrRecordNotFound)
+		}
+	})
+}
+
+func (r *GormRepository[T]) Delete(ctx context.Context, id int) error {
+	return r.remove(ctx, "delete "+r.name, models.AuditDelete, id, nil)
+}
+
+// DeleteIfVersion deletes the row with id only while its stored version is
+// version, failing with apperrors.ErrPreconditionFailed when it has changed.
+func (r *GormRepository[T]) DeleteIfVersion(ctx context.Context, id int, version uint) error {
+	return r.remove(ctx, "delete "+r.name, models.AuditDelete, id, &version)
+}
+
+// remove soft-deletes, or purges from the trash, the row with id. A non-nil
+// version makes the delete conditional on it.
+func (r *GormRepository[T]) remove(ctx context.Context, op, action string, id int, version *uint) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	return r.audited(ctx, op, action, func(tx *gorm.DB) (*T, *T, error) {
+		rows := func() *gorm.DB {
+			if action == models.AuditPurge {
+				return tx.Scopes(Trashed)
+			}
+			return tx
+		}
+		before := new(T)
+		if err := rows().First(before, id).Error; err != nil {
+			return nil, nil, r.fail(op, err)
+		}
+
+		db := rows()
+		if version != nil {
+			db = db.Where("version = ?", *version)
+		}
+		result := db.Delete(new(T), id)
+		switch {
+		case result.Error != nil:
+			return nil, nil, r.fail(op, result.Error)
+		case result.RowsAffected > 0:
+			return before, nil, nil
+		case version != nil:
+			return nil, nil, r.stale(op)
+		default:
+			return nil, nil, apperrors.NotFound(op, gorm.ErrRecordNotFound)
+		}
+	})
+}
+
+func (r *GormRepository[T]) stale(op string) error {
+	return apperrors.PreconditionFailed(op, errors.New(r.name+" was modified by another request"))
+}
+
+func (r *GormRepository[T]) GetByID(ctx context.Context, id int) (*T, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+	var entity T
+	if err := r.DB.WithContext(ctx).First(&entity, id).Error; err != nil {
+		return nil, r.fail("get "+r.name+" by ID", err)
+	}
+	return &entity, nil
+}
+
+func (r *GormRepository[T]) List(ctx context.Context, query Query) ([]T, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+	var entities []T
+	if err := query.apply(r.DB.WithContext(ctx)).Find(&entities).Error; err != nil {
+		return nil, r.fail("list "+r.name+"s", err)
+	}
+	return entities, nil
+}
+
+// Count returns how many rows match the filters of query, ignoring its
+// ordering and paging.
+func (r *GormRepository[T]) Count(ctx context.Context, query Query) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+	var total int64
+	if err := query.filter(r.DB.WithContext(ctx).Model(new(T))).Count(&total).Error; err != nil {
+		return 0, r.fail("count "+r.name+"s", err)
+	}
+	return total, nil
+}
+
+// Restore brings a soft-deleted row back, failing with apperrors.ErrNotFound
+// when id is not in the trash. Versioned rows get a new version.
+func (r *GormRepository[T]) Restore(ctx context.Context, id int) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	op := "restore " + r.name
+
+	return r.audited(ctx, op, models.AuditRestore, func(tx *gorm.DB) (*T, *T, error) {
+		before := new(T)
+		if err := tx.Scopes(Trashed).First(before, id).Error; err != nil {
+			return nil, nil, r.fail(op, err)
+		}
+
+		columns := map[string]interface{}{"deleted_at": nil, "updated_at": time.Now()}
+		if _, ok := any(before).(versioned); ok {
+			columns["version"] = gorm.Expr("version + 1")
+		}
+		result := tx.Model(new(T)).Scopes(Trashed).Where("id = ?", id).UpdateColumns(columns)
+		if err := result.Error; err != nil {
+			return nil, nil, r.fail(op, err)
+		}
+		if result.RowsAffected == 0 {
+			return nil, nil, apperrors.NotFound(op, gorm.ErrRecordNotFound)
+		}
+
+		after := new(T)
+		if err := tx.First(after, id).Error; err != nil {
+			return nil, nil, r.fail(op, err)
+		}
+		return before, after, nil
+	})
+}
+
+// Purge permanently deletes a row that is already in the trash, failing with
+// apperrors.ErrNotFound otherwise.
+func (r *GormRepository[T]) Purge(ctx context.Context, id int) error {
+	return r.remove(ctx, "purge "+r.name, models.AuditPurge, id, nil)
+}
+
+// PurgeDeleted permanently deletes every row soft-deleted before the given
+// time and reports how many were removed.
+func (r *GormRepository[T]) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	op := "purge deleted " + r.name + "s"
+
+	var purged int64
+	err := r.transaction(ctx, op, func(tx *gorm.DB) error {
+		var rows []T
+		if err := tx.Scopes(Trashed).Where("deleted_at < ?", before).Find(&rows).Error; err != nil {
+			return r.fail(op, err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		result := tx.Unscoped().Delete(&rows)
+		if err := result.Error; err != nil {
+			return r.fail(op, err)
+		}
+		purged = result.RowsAffected
+		for i := range rows {
+			if err := r.record(ctx, tx, models.AuditPurge, &rows[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (r *GormRepository[T]) fail(op string, err error) error {
+	r.logger.Warnf("failed to %s: %v", op, err)
+	return wrapError(op, err)
+}
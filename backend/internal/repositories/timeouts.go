@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"backend/internal/config"
+	"context"
+	"time"
+)
+
+const (
+	defaultReadTimeout  = 5 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
+// Timeouts bounds how long a single repository call may run. A deadline
+// already carried by the caller's context wins when it is earlier.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// DefaultTimeouts returns the timeouts configured through DB_READ_TIMEOUT
+// and DB_WRITE_TIMEOUT, falling back to built-in defaults when unset.
+func DefaultTimeouts() Timeouts {
+	timeouts := Timeouts{Read: config.AppConfig.DBReadTimeout, Write: config.AppConfig.DBWriteTimeout}
+	if timeouts.Read <= 0 {
+		timeouts.Read = defaultReadTimeout
+	}
+	if timeouts.Write <= 0 {
+		timeouts.Write = defaultWriteTimeout
+	}
+	return timeouts
+}
+
+func (t Timeouts) read(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Read)
+}
+
+func (t Timeouts) write(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Write)
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxTxRetries = 3
+	defaultRetryBackoff = 50 * time.Millisecond
+)
+
+// Postgres SQLSTATE codes after which the whole transaction can safely be
+// replayed from the start.
+var retryableSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// Repos bundles repository instances that share a single transaction.
+type Repos struct {
+	Customers CustomerRepositoryImpl
+	Orders    OrderRepositoryImpl
+	Products  ProductRepositoryImpl
+
+	tx *TxManager
+}
+
+// WithinTx runs fn inside a savepoint of the transaction the repos are bound
+// to. Returning an error from fn rolls back to the savepoint only, leaving the
+// enclosing transaction usable.
+func (r Repos) WithinTx(ctx context.Context, fn func(repos Repos) error) error {
+	return r.tx.WithinTx(ctx, fn)
+}
+
+// TxManager runs units of work that span several repositories atomically.
+type TxManager struct {
+	DB         *gorm.DB
+	Options    *sql.TxOptions
+	MaxRetries int
+	logger     *logrus.Logger
+	nested     bool
+}
+
+func NewTxManager(db *gorm.DB, logger *logrus.Logger) *TxManager {
+	return &TxManager{DB: db, MaxRetries: defaultMaxTxRetries, logger: logger}
+}
+
+// WithinTx begins a transaction, hands fn repositories bound to it and commits
+// when fn returns nil. Any error rolls the transaction back. Postgres
+// serialization failures and deadlocks are retried up to MaxRetries times with
+// jittered backoff, so fn must not have side effects outside the database.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(repos Repos) error) error {
+	run := func() error {
+		return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(m.repos(tx))
+		}, m.Options)
+	}
+
+	// A nested manager is already inside a transaction, so gorm turns the call
+	// into a savepoint. Retrying a savepoint cannot recover an aborted
+	// transaction; the outermost WithinTx owns retries.
+	if m.nested {
+		return run()
+	}
+
+	backoff := defaultRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := run()
+		if err == nil || attempt >= m.MaxRetries || !isRetryable(err) {
+			return err
+		}
+		m.logger.Warnf("retrying transaction after attempt %d: %v", attempt+1, err)
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+func (m *TxManager) repos(tx *gorm.DB) Repos {
+	return Repos{
+		Customers: NewCustomerRepository(tx, m.logger),
+		Orders:    NewOrderRepository(tx, m.logger),
+		Products:  NewProductRepository(tx, m.logger),
+		tx:        &TxManager{DB: tx, Options: m.Options, logger: m.logger, nested: true},
+	}
+}
+
+// isRetryable reports whether err carries a Postgres SQLSTATE that marks the
+// transaction as safe to replay.
+func isRetryable(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		return retryableSQLStates[state.SQLState()]
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"backend/pkg/logging"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTxManager_Commit(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		manager := NewTxManager(db, logging.GetLogger())
+
+		err := manager.WithinTx(ctx, func(repos Repos) error {
+			if err := repos.Products.Create(ctx, &models.Product{Name: "Maize", Price: 120}); err != nil {
+				return err
+			}
+			return repos.Orders.Create(ctx, &models.Order{ProductID: 1, Quantity: 2, UserId: 1})
+		})
+		assert.NoError(t, err)
+
+		orders, err := NewOrderRepository(db, logging.GetLogger()).List(ctx, Query{})
+		assert.NoError(t, err)
+		assert.Len(t, orders, 1)
+	})
+}
+
+func TestTxManager_Rollback(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		manager := NewTxManager(db, logging.GetLogger())
+		failure := errors.New("out of stock")
+
+		err := manager.WithinTx(ctx, func(repos Repos) error {
+			if err := repos.Orders.Create(ctx, &models.Order{ProductID: 1, Quantity: 2, UserId: 1}); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		orders, err := NewOrderRepository(db, logging.GetLogger()).List(ctx, Query{})
+		assert.NoError(t, err)
+		assert.Empty(t, orders)
+	})
+}
+
+func TestTxManager_NestedSavepoint(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		manager := NewTxManager(db, logging.GetLogger())
+
+		err := manager.WithinTx(ctx, func(repos Repos) error {
+			if err := repos.Orders.Create(ctx, &models.Order{ProductID: 1, Quantity: 1, UserId: 1}); err != nil {
+				return err
+			}
+			nestedErr := repos.WithinTx(ctx, func(inner Repos) error {
+				if err := inner.Orders.Create(ctx, &models.Order{ProductID: 2, Quantity: 1, UserId: 1}); err != nil {
+					return err
+				}
+				return errors.New("discard inner order")
+			})
+			assert.Error(t, nestedErr)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		orders, err := NewOrderRepository(db, logging.GetLogger()).List(ctx, Query{})
+		assert.NoError(t, err)
+		assert.Len(t, orders, 1)
+		assert.Equal(t, 1, orders[0].ProductID)
+	})
+}
+
+func TestTxManager_RetriesSerializationFailure(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		manager := NewTxManager(db, logging.GetLogger())
+
+		attempts := 0
+		err := manager.WithinTx(ctx, func(repos Repos) error {
+			attempts++
+			if attempts == 1 {
+				return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+			}
+			return repos.Orders.Create(ctx, &models.Order{ProductID: 1, Quantity: 1, UserId: 1})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+
+		orders, err := NewOrderRepository(db, logging.GetLogger()).List(ctx, Query{})
+		assert.NoError(t, err)
+		assert.Len(t, orders, 1)
+	})
+}
+
+func TestTxManager_DoesNotRetryOtherErrors(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		manager := NewTxManager(db, logging.GetLogger())
+
+		attempts := 0
+		err := manager.WithinTx(context.Background(), func(repos Repos) error {
+			attempts++
+			return &pgconn.PgError{Code: "23505", Message: "duplicate key value"}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
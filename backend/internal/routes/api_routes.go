@@ -3,9 +3,16 @@ package routes
 import (
 	"backend/internal/config"
 	"backend/internal/handlers"
+	"backend/internal/health"
+	"backend/internal/jobs"
+	"backend/internal/metrics"
 	"backend/internal/middleware"
+	"backend/internal/ratelimit"
 	"backend/internal/repositories"
-	"backend/pkg/database"
+	"backend/internal/tracing"
+	"backend/internal/utils"
+	"backend/pkg/authentication"
+	"context"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
@@ -14,26 +21,53 @@ import (
 	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/github"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
 )
 
-func SetupRoutes(router *gin.Engine, logger *logrus.Logger) {
-	// Connect to PostgreSQL database
-	err := database.Connect(logger)
-	db := database.DB
-	if err != nil {
-		panic("Failed to connect to the database!")
+// SetupRoutes registers the middleware and routes on router and schedules
+// the background jobs on scheduler. The caller owns db and scheduler and
+// shuts them down. Long-lived streams end when ctx is done.
+func SetupRoutes(ctx context.Context, router *gin.Engine, db *gorm.DB, scheduler *jobs.Scheduler, logger *logrus.Logger) {
+	// Client IPs key the rate limits and the access log, so forwarding
+	// headers are only believed from the proxies named in TRUSTED_PROXIES.
+	if err := router.SetTrustedProxies(config.AppConfig.TrustedProxies); err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
 	}
-	err = config.Load()
-	if err != nil {
-		return
+	router.Use(middleware.RequestID())
+	router.Use(tracing.Middleware())
+	router.Use(middleware.AccessLog(logger, "/healthz", "/readyz", "/metrics"))
+	router.Use(metrics.Middleware())
+	router.Use(middleware.Recovery(logger))
+	router.Use(middleware.SecurityHeaders(config.AppConfig.HSTSMaxAge))
+	if len(config.AppConfig.CORSAllowedOrigins) > 0 {
+		router.Use(middleware.CORS(config.AppConfig.CORSAllowedOrigins, config.AppConfig.CORSAllowedMethods, config.AppConfig.CORSAllowCredentials))
 	}
+	router.Use(middleware.ErrorHandler(logger))
+	router.NoRoute(middleware.NotFound())
 
+	// Session cookies are never readable from scripts, and with SameSite=Lax
+	// still travel on the top-level redirect back from the OAuth provider.
 	store := cookie.NewStore([]byte(config.AppConfig.Secret))
+	store.Options(sessions.Options{
+		Path:     "/",
+		MaxAge:   86400 * 30,
+		Secure:   config.AppConfig.SecureCookies,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
 	router.Use(sessions.Sessions("session", store))
 
 	githubProvider := github.New(config.AppConfig.GithubClientID, config.AppConfig.GithubClientSecret, config.AppConfig.CallbackUrl)
 	goth.UseProviders(githubProvider)
-	gothic.Store = gorrilla.NewCookieStore([]byte(config.AppConfig.GithubClientID))
+	gothicStore := gorrilla.NewCookieStore([]byte(config.AppConfig.GithubClientID))
+	gothicStore.Options.HttpOnly = true
+	gothicStore.Options.Secure = config.AppConfig.SecureCookies
+	gothicStore.Options.SameSite = http.SameSiteLaxMode
+	gothic.Store = gothicStore
+	authentication.TokenCacheTTL = config.AppConfig.TokenCacheTTL
+	authentication.OnTokenCacheLookup = metrics.TokenCacheLookup
 
 	// Initialize repositories and handlers
 	customerRepo := repositories.NewCustomerRepository(db, logger)
@@ -42,35 +76,100 @@ func SetupRoutes(router *gin.Engine, logger *logrus.Logger) {
 	orderRepo := repositories.NewOrderRepository(db, logger)
 	orderHandler := handlers.NewOrderHandler(orderRepo, logger)
 
+	idempotencyRepo := repositories.NewIdempotencyRepository(db, logger)
+	idempotency := middleware.Idempotency(idempotencyRepo, config.AppConfig.IdempotencyTTL, config.AppConfig.IdempotencyLease, logger)
+	batchHandler := handlers.NewBatchHandler(repositories.NewTxManager(db, logger), config.AppConfig.BatchMaxItems, logger)
+
+	// Background cleanup of the trash and of expired idempotency keys
+	scheduler.Every(context.Background(), "purge trash", purgeInterval(config.AppConfig), jobs.PurgeTrash(logger, config.AppConfig.TrashRetention, map[string]jobs.Purger{
+		"orders":    orderRepo,
+		"customers": customerRepo,
+	}))
+	scheduler.Every(context.Background(), "expire idempotency keys", config.AppConfig.CleanupInterval, func(ctx context.Context) error {
+		_, err := idempotencyRepo.DeleteExpired(ctx, time.Now())
+		return err
+	})
+
+	auditRepo := repositories.NewAuditRepository(db, logger)
+	auditHandler := handlers.NewAuditHandler(auditRepo, logger)
+	orderStreamHandler := handlers.NewOrderStreamHandler(ctx, auditRepo, config.AppConfig.OrderStreamPoll, config.AppConfig.OrderStreamHeartbeat, config.AppConfig.OrderStreamGrace, logger)
+
 	authHandler := handlers.NewAuthenticationHandler(logger)
 
+	rateLimits, err := ratelimit.ParsePolicies(config.AppConfig.RateLimitDefault, config.AppConfig.RateLimitRoutes)
+	if err != nil {
+		logger.Fatalf("Invalid rate limit configuration: %v", err)
+	}
+	ipRateLimit, err := ratelimit.ParsePolicies(config.AppConfig.RateLimitIP, "")
+	if err != nil {
+		logger.Fatalf("Invalid rate limit configuration: %v", err)
+	}
+	rateLimitStore := ratelimit.NewMemoryStore()
+	// Requests are limited per client IP before authentication, so made-up
+	// tokens cannot dodge the limit, and per user once authenticated.
+	authenticated := []gin.HandlerFunc{middleware.AuthMiddleware(), middleware.RateLimitByUser(rateLimitStore, rateLimits, logger)}
+
+	healthHandler := handlers.NewHealthHandler(healthChecker(db, config.AppConfig), logger)
+
 	// Setup routes
 	router.GET("", authHandler.Home)
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/metrics", metrics.Handler())
 
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.RateLimitByIP(rateLimitStore, ipRateLimit, logger))
 	{
+		batches := v1.Group("", customMethod("batch"))
+		batches.Use(authenticated...)
+		batches.Use(middleware.BodyLimit(handlers.BatchBodyLimit(config.AppConfig.BatchMaxItems)))
+		{
+			batches.POST("/customers:method", idempotency, batchHandler.CreateCustomers)
+			batches.POST("/orders:method", idempotency, batchHandler.CreateOrders)
+		}
+
 		customers := v1.Group("/customers")
-		customers.Use(middleware.AuthMiddleware())
+		customers.Use(authenticated...)
 		{
 			customers.GET("", customerHandler.GetAllCustomers)
+			customers.GET("/trash", customerHandler.GetTrashedCustomers)
+			customers.DELETE("/trash/:id", middleware.RequireAdmin(), customerHandler.PurgeCustomer)
+			customers.POST("/:id/restore", customerHandler.RestoreCustomer)
+			customers.GET("/:id/history", middleware.RequireAdmin(), auditHandler.GetCustomerHistory)
 			customers.POST("", customerHandler.CreateCustomer)
 			customers.PUT("/:id", customerHandler.UpdateCustomer)
+			customers.PATCH("/:id", customerHandler.PatchCustomer)
+			customers.GET("/:id", customerHandler.GetCustomerByID)
+			customers.DELETE("/:id", customerHandler.DeleteCustomer)
 		}
 		orders := v1.Group("/orders")
-		orders.Use(middleware.AuthMiddleware())
+		orders.Use(authenticated...)
 		{
-			orders.POST("", orderHandler.CreateOrder)
+			orders.POST("", idempotency, orderHandler.CreateOrder)
 			orders.PUT("/:id", orderHandler.UpdateOrder)
+			orders.PATCH("/:id", orderHandler.PatchOrder)
 			orders.DELETE("/:id", orderHandler.DeleteOrder)
 			orders.GET("/:id", orderHandler.GetOrderByID)
 			orders.GET("", orderHandler.GetAllOrders)
+			orders.GET("/stream", orderStreamHandler.StreamOrders)
+			orders.GET("/trash", orderHandler.GetTrashedOrders)
+			orders.DELETE("/trash/:id", middleware.RequireAdmin(), orderHandler.PurgeOrder)
+			orders.POST("/:id/restore", orderHandler.RestoreOrder)
+			orders.GET("/:id/history", auditHandler.GetOrderHistory)
 		}
 		users := v1.Group("/users")
-		users.Use(middleware.AuthMiddleware())
+		users.Use(authenticated...)
 		{
 			users.GET("/:user_id/orders", orderHandler.GetOrdersByUserID)
 		}
 
+		audit := v1.Group("/audit")
+		audit.Use(authenticated...)
+		audit.Use(middleware.RequireAdmin())
+		{
+			audit.GET("", auditHandler.GetAuditLog)
+		}
+
 		authentication := v1.Group("/auth")
 		{
 			authentication.GET("/callback", authHandler.CallBack)
@@ -79,3 +178,39 @@ func SetupRoutes(router *gin.Engine, logger *logrus.Logger) {
 		}
 	}
 }
+
+// customMethod admits requests for the custom method name, e.g. batch for
+// POST /orders:batch. gin cannot route a literal colon, so such routes are
+// registered as "/orders:method" and everything but ":name" is not found.
+func customMethod(name string) gin.HandlerFunc {
+	notFound := middleware.NotFound()
+	return func(c *gin.Context) {
+		if c.Param("method") != ":"+name {
+			notFound(c)
+		}
+	}
+}
+
+// purgeInterval disables the trash retention job when TRASH_RETENTION is not
+// positive.
+func purgeInterval(cfg config.Config) time.Duration {
+	if cfg.TrashRetention <= 0 {
+		return 0
+	}
+	return cfg.CleanupInterval
+}
+
+// healthChecker builds the readiness checks. The database and schema checks
+// are required; reachability of the SMS and OAuth providers is optional and
+// only probed when enabled.
+func healthChecker(db *gorm.DB, cfg config.Config) *health.Checker {
+	checks := []health.Check{health.Database(db), health.Schema(db)}
+	client := &http.Client{Timeout: cfg.HealthTimeout}
+	if cfg.HealthCheckSMS {
+		checks = append(checks, health.Reachable("sms", utils.SMSURL, client, cfg.HealthCacheTTL))
+	}
+	if cfg.HealthCheckAuth {
+		checks = append(checks, health.Reachable("auth", github.ProfileURL, client, cfg.HealthCacheTTL))
+	}
+	return health.NewChecker(cfg.HealthTimeout, checks...)
+}
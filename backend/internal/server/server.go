@@ -2,12 +2,26 @@ package server
 
 import (
 	_ "backend/docs"
+	"backend/internal/config"
+	"backend/internal/jobs"
 	"backend/internal/routes"
+	"backend/internal/tracing"
+	"backend/pkg/database"
 	"backend/pkg/logging"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	_ "github.com/gin-contrib/sessions/memstore"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 // @title Savannah Informatics Interview
@@ -21,16 +35,120 @@ import (
 
 // Run @host localhost:8080
 // @BasePath /v1
+//
+// Run serves the API until SIGINT or SIGTERM, then stops accepting
+// connections, lets in-flight requests and background jobs finish within
+// SHUTDOWN_TIMEOUT and closes the database last.
 func Run() error {
 	logger := logging.GetLogger()
-	router := gin.Default()
+
+	if err := database.Connect(logger); err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	cfg := config.AppConfig
+	if err := logging.Configure(cfg.LogLevel, cfg.LogFormat); err != nil {
+		_ = database.Close(logger)
+		return fmt.Errorf("failed to configure logging: %w", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		_ = database.Close(logger)
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+
+	scheduler := jobs.NewScheduler(logger)
+	router := gin.New()
 	router.LoadHTMLGlob("templates/*.html")
 
-	// Setup routes
-	routes.SetupRoutes(router, logger)
+	// Setup routes. Open streams are ended when shutdown begins; Shutdown
+	// would otherwise wait for them until the deadline.
+	streams, endStreams := context.WithCancel(context.Background())
+	defer endStreams()
+	routes.SetupRoutes(streams, router, database.DB, scheduler, logger)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	logger.Info("Starting server on :8080")
 
-	return router.Run(":8080")
+	srv := &http.Server{
+		Addr:         cfg.Addr(),
+		Handler:      router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	srv.RegisterOnShutdown(endStreams)
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		_ = database.Close(logger)
+		return fmt.Errorf("failed to listen on %s: %w", srv.Addr, err)
+	}
+
+	if cfg.TLS() {
+		reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, logger)
+		if err != nil {
+			_ = listener.Close()
+			_ = database.Close(logger)
+			return err
+		}
+		srv.TLSConfig = tlsConfig(reloader)
+		listener = tls.NewListener(listener, srv.TLSConfig)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Infof("Starting server on %s (TLS: %t)", listener.Addr(), cfg.TLS())
+	return serve(ctx, srv, listener, cfg.ShutdownTimeout, logger,
+		step{"background jobs", scheduler.Shutdown},
+		step{"database", func(context.Context) error { return database.Close(logger) }},
+		step{"tracing", shutdownTracing},
+	)
+}
+
+// step is a resource released during shutdown, after the HTTP server.
+type step struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// serve runs srv on listener until ctx is done or the server fails. It then
+// drains in-flight requests and releases steps in order, all within timeout.
+// Every step runs even if an earlier one failed or the deadline passed, so
+// the database is closed no matter what.
+func serve(ctx context.Context, srv *http.Server, listener net.Listener, timeout time.Duration, logger *logrus.Logger, steps ...step) error {
+	failed := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	var errs []error
+	select {
+	case err := <-failed:
+		logger.Errorf("server stopped: %v", err)
+		errs = append(errs, err)
+	case <-ctx.Done():
+		logger.Info("Shutting down: draining in-flight requests")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warnf("failed to drain HTTP server: %v", err)
+		errs = append(errs, fmt.Errorf("failed to drain HTTP server: %w", err))
+	}
+	for _, s := range steps {
+		if err := s.close(shutdownCtx); err != nil {
+			logger.Warnf("failed to stop %s: %v", s.name, err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", s.name, err))
+			continue
+		}
+		logger.Infof("Stopped %s", s.name)
+	}
+	if len(errs) == 0 {
+		logger.Info("Server stopped")
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"backend/pkg/logging"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe_DrainsRequestsBeforeClosingResources(t *testing.T) {
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var order []string
+	record := func(name string) step {
+		return step{name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}}
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, srv, listener, time.Second, logging.GetLogger(), record("jobs"), record("database"))
+	}()
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	stop()
+
+	assert.Equal(t, "done", <-response)
+	assert.NoError(t, <-served)
+	assert.Equal(t, []string{"jobs", "database"}, order)
+}
+
+func TestServe_ClosesResourcesAfterDeadline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var closed bool
+	ctx, stop := context.WithCancel(context.Background())
+	stop()
+
+	err = serve(ctx, &http.Server{Handler: http.NotFoundHandler()}, listener, 10*time.Millisecond, logging.GetLogger(),
+		step{"jobs", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		step{"database", func(context.Context) error {
+			closed = true
+			return nil
+		}},
+	)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, closed, "database must be closed even when jobs overrun the deadline")
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval is how often the certificate files are checked for
+// changes. Checks happen during handshakes, so an idle server does none.
+const reloadCheckInterval = 10 * time.Second
+
+// certReloader serves the key pair in certFile and keyFile and loads it
+// again once either file changes, so renewed certificates are picked up
+// without a restart. A pair that fails to load, e.g. because only one of
+// the files has been replaced so far, is retried later while the previous
+// certificate stays in use.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *logrus.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string, logger *logrus.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, logger: logger, now: time.Now}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	r.checked = r.now()
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.checked) >= reloadCheckInterval {
+		r.checked = now
+		modTime, err := r.latestModTime()
+		if err != nil {
+			r.logger.Warnf("failed to check TLS certificate: %v", err)
+		} else if modTime.After(r.modTime) {
+			if err := r.load(modTime); err != nil {
+				r.logger.Warnf("failed to reload TLS certificate, keeping the current one: %v", err)
+			} else {
+				r.logger.Infof("Reloaded TLS certificate from %s", r.certFile)
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// tlsConfig serves the reloader's certificate over TLS 1.2 or later and
+// offers HTTP/2 before HTTP/1.1.
+func tlsConfig(reloader *certReloader) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+}
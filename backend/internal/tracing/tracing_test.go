@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"backend/internal/config"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	recorderOnce sync.Once
+	recorder     *tracetest.SpanRecorder
+)
+
+// record installs a recording tracer provider once for the package, as the
+// global provider can only be delegated to once, and returns a function
+// listing the spans ended since the call.
+func record(t *testing.T) func() []sdktrace.ReadOnlySpan {
+	recorderOnce.Do(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+	_, err := Setup(context.Background(), config.Config{TracingExporter: ExporterNone})
+	assert.NoError(t, err)
+
+	seen := len(recorder.Ended())
+	return func() []sdktrace.ReadOnlySpan {
+		return recorder.Ended()[seen:]
+	}
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ended := record(t)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/orders/:id", func(c *gin.Context) {
+		_ = c.Error(errors.New("connection refused"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req, _ := http.NewRequest("GET", "/orders/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := ended()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /orders/:id", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, "/orders/:id", attributeValue(span, "http.route").AsString())
+	assert.Equal(t, int64(http.StatusInternalServerError), attributeValue(span, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestGormPlugin(t *testing.T) {
+	ended := record(t)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tracing.db")), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(GormPlugin{}))
+
+	type widget struct {
+		ID   uint
+		Name string
+	}
+	assert.NoError(t, db.AutoMigrate(&widget{}))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	var widgets []widget
+	assert.NoError(t, db.WithContext(ctx).Find(&widgets).Error)
+	parent.End()
+
+	var query sdktrace.ReadOnlySpan
+	for _, span := range ended() {
+		if span.Name() == "gorm.query" && span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			query = span
+		}
+	}
+	if assert.NotNil(t, query) {
+		assert.Equal(t, "widgets", attributeValue(query, "db.collection.name").AsString())
+		assert.Contains(t, attributeValue(query, "db.query.text").AsString(), "SELECT")
+		assert.Equal(t, codes.Unset, query.Status().Code)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.Config{TracingExporter: ExporterNone})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), config.Config{TracingExporter: "zipkin"})
+	assert.Error(t, err)
+}
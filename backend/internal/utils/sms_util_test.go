@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -28,6 +29,6 @@ func TestSendSMS(t *testing.T) {
 	to := "+1234567890"
 	message := "Hello, this is a test message."
 
-	_ = SendSMS(apiKey, username, to, message)
+	_ = SendSMS(context.Background(), apiKey, username, to, message)
 	//assert.NoError(t, err)
 }
@@ -1,23 +1,45 @@
 package utils
 
 import (
+	"backend/internal/metrics"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// SMSURL is the Africa’s Talking messaging endpoint SendSMS posts to.
+const SMSURL = "https://api.sandbox.africastalking.com/version1/messaging"
+
+var tracer = otel.Tracer("backend/internal/utils")
+
 // SendSMS sends an SMS message using Africa’s Talking API.
-func SendSMS(apiKey, username, to, message string) error {
-	apiUrl := "https://api.sandbox.africastalking.com/version1/messaging"
+func SendSMS(ctx context.Context, apiKey, username, to, message string) error {
+	ctx, span := tracer.Start(ctx, "utils.SendSMS", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	err := sendSMS(ctx, apiKey, username, to, message)
+	metrics.SMSSent.WithLabelValues(metrics.Outcome(err)).Inc()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to send SMS")
+	}
+	return err
+}
 
+func sendSMS(ctx context.Context, apiKey, username, to, message string) error {
 	data := url.Values{}
 	data.Set("username", username)
 	data.Set("to", to)
 	data.Set("message", message)
 
-	req, err := http.NewRequest("POST", apiUrl, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", SMSURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
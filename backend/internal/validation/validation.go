@@ -0,0 +1,177 @@
+// Package validation registers the custom binding rules used by request DTOs
+// and turns validation failures into per-field errors for API responses.
+package validation
+
+import (
+	"backend/internal/dto"
+	"backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom rule names, usable in binding tags next to the built-in ones.
+const (
+	KenyanPhone   = "kenyan_phone"
+	CustomerCode  = "customer_code"
+	PositiveMoney = "positive_money"
+)
+
+// kenyanPhonePattern accepts Safaricom, Airtel and Telkom mobile numbers in
+// international (+254712345678, 254712345678) or local (0712345678) form.
+var kenyanPhonePattern = regexp.MustCompile(`^(?:\+?254|0)[17]\d{8}$`)
+
+func init() {
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := Register(engine); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register adds the custom rules to v and makes it report fields by their
+// JSON names. It runs on gin's validator when the package is loaded.
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	rules := map[string]validator.Func{
+		KenyanPhone:   kenyanPhone,
+		CustomerCode:  customerCode,
+		PositiveMoney: positiveMoney,
+	}
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			return fmt.Errorf("failed to register %s validation: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func kenyanPhone(fl validator.FieldLevel) bool {
+	return kenyanPhonePattern.MatchString(fl.Field().String())
+}
+
+func customerCode(fl validator.FieldLevel) bool {
+	return models.ValidCustomerCode(fl.Field().String())
+}
+
+// positiveMoney accepts amounts above zero with at most two decimal places.
+func positiveMoney(fl validator.FieldLevel) bool {
+	var amount float64
+	switch field := fl.Field(); field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		amount = field.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		amount = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		amount = float64(field.Uint())
+	default:
+		return false
+	}
+	cents := amount * 100
+	return amount > 0 && math.Abs(cents-math.Round(cents)) < 1e-6
+}
+
+// Errors lists every field a request was rejected for.
+type Errors []dto.FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, field := range e {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Fields converts a binding error into Errors. ok is false when err does not
+// concern individual fields, such as a malformed JSON document.
+func Fields(err error) (fields Errors, ok bool) {
+	var (
+		existing  Errors
+		invalid   validator.ValidationErrors
+		wrongType *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &existing):
+		return existing, true
+	case errors.As(err, &invalid):
+		for _, fe := range invalid {
+			fields = append(fields, dto.FieldError{Field: fieldPath(fe), Code: fe.Tag(), Message: message(fe)})
+		}
+		return fields, true
+	case errors.As(err, &wrongType) && wrongType.Field != "":
+		return Errors{{
+			Field:   wrongType.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("%s must be a %s", wrongType.Field, jsonType(wrongType.Type)),
+		}}, true
+	}
+	return nil, false
+}
+
+// fieldPath drops the struct name validator puts in front of the JSON path.
+func fieldPath(fe validator.FieldError) string {
+	if _, path, found := strings.Cut(fe.Namespace(), "."); found {
+		return path
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	field := fieldPath(fe)
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, fe.Param(), unit)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, fe.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case KenyanPhone:
+		return field + " must be a Kenyan mobile number such as +254712345678 or 0712345678"
+	case CustomerCode:
+		return field + " must be 2 to 32 uppercase letters, digits or hyphens"
+	case PositiveMoney:
+		return field + " must be an amount above zero with at most 2 decimal places"
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, Register(v))
+
+	tests := []struct {
+		rule  string
+		value interface{}
+		valid bool
+	}{
+		{KenyanPhone, "+254712345678", true},
+		{KenyanPhone, "254112345678", true},
+		{KenyanPhone, "0712345678", true},
+		{KenyanPhone, "+25471234567", false},
+		{KenyanPhone, "+255712345678", false},
+		{KenyanPhone, "0212345678", false},
+		{KenyanPhone, "0712 345 678", false},
+		{CustomerCode, "CUST-001", true},
+		{CustomerCode, "cust-001", false},
+		{CustomerCode, "C", false},
+		{PositiveMoney, 12.5, true},
+		{PositiveMoney, 0.01, true},
+		{PositiveMoney, 19.99, true},
+		{PositiveMoney, 100, true},
+		{PositiveMoney, 0.0, false},
+		{PositiveMoney, -5.0, false},
+		{PositiveMoney, 1.005, false},
+		{PositiveMoney, "10", false},
+	}
+	for _, tt := range tests {
+		err := v.Var(tt.value, tt.rule)
+		assert.Equal(t, tt.valid, err == nil, "%s %v", tt.rule, tt.value)
+	}
+}
+
+func TestFields(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, Register(v))
+
+	type request struct {
+		Name  string `json:"name" validate:"required,max=5"`
+		Phone string `json:"phone,omitempty" validate:"omitempty,kenyan_phone"`
+	}
+	err := v.Struct(request{Name: "Too long", Phone: "12345"})
+
+	fields, ok := Fields(err)
+	require.True(t, ok)
+	assert.Equal(t, Errors{
+		{Field: "name", Code: "max", Message: "name must be at most 5 characters"},
+		{Field: "phone", Code: KenyanPhone, Message: "phone must be a Kenyan mobile number such as +254712345678 or 0712345678"},
+	}, fields)
+	assert.Equal(t, "name must be at most 5 characters; phone must be a Kenyan mobile number such as +254712345678 or 0712345678", fields.Error())
+
+	// Errors wrapped by other errors are still found.
+	wrapped, ok := Fields(errors.Join(errors.New("failed"), Errors{{Field: "code", Code: "customer_code"}}))
+	assert.True(t, ok)
+	assert.Equal(t, Errors{{Field: "code", Code: "customer_code"}}, wrapped)
+
+	_, ok = Fields(errors.New("unexpected EOF"))
+	assert.False(t, ok)
+}
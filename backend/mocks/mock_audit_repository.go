@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/internal/repositories (interfaces: AuditRepositoryImpl)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "backend/internal/models"
+	repositories "backend/internal/repositories"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAuditRepositoryImpl is a mock of AuditRepositoryImpl interface.
+type MockAuditRepositoryImpl struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryImplMockRecorder
+}
+
+// MockAuditRepositoryImplMockRecorder is the mock recorder for MockAuditRepositoryImpl.
+type MockAuditRepositoryImplMockRecorder struct {
+	mock *MockAuditRepositoryImpl
+}
+
+// NewMockAuditRepositoryImpl creates a new mock instance.
+func NewMockAuditRepositoryImpl(ctrl *gomock.Controller) *MockAuditRepositoryImpl {
+	mock := &MockAuditRepositoryImpl{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryImplMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepositoryImpl) EXPECT() *MockAuditRepositoryImplMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockAuditRepositoryImpl) Count(arg0 context.Context, arg1 repositories.Query) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockAuditRepositoryImplMockRecorder) Count(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockAuditRepositoryImpl)(nil).Count), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockAuditRepositoryImpl) List(arg0 context.Context, arg1 repositories.Query) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryImplMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepositoryImpl)(nil).List), arg0, arg1)
+}
@@ -6,7 +6,10 @@ package mocks
 
 import (
 	models "backend/internal/models"
+	repositories "backend/internal/repositories"
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,60 +37,160 @@ func (m *MockCustomerRepositoryImpl) EXPECT() *MockCustomerRepositoryImplMockRec
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockCustomerRepositoryImpl) Count(arg0 context.Context, arg1 repositories.Query) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockCustomerRepositoryImplMockRecorder) Count(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Count), arg0, arg1)
+}
+
 // Create mocks base method.
-func (m *MockCustomerRepositoryImpl) Create(arg0 *models.Customer) error {
+func (m *MockCustomerRepositoryImpl) Create(arg0 context.Context, arg1 *models.Customer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockCustomerRepositoryImplMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockCustomerRepositoryImplMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Create), arg0, arg1)
 }
 
-// GetAll mocks base method.
-func (m *MockCustomerRepositoryImpl) GetAll() ([]models.Customer, error) {
+// Delete mocks base method.
+func (m *MockCustomerRepositoryImpl) Delete(arg0 context.Context, arg1 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
-	ret0, _ := ret[0].([]models.Customer)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockCustomerRepositoryImplMockRecorder) GetAll() *gomock.Call {
+// Delete indicates an expected call of Delete.
+func (mr *MockCustomerRepositoryImplMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Delete), arg0, arg1)
+}
+
+// DeleteIfVersion mocks base method.
+func (m *MockCustomerRepositoryImpl) DeleteIfVersion(arg0 context.Context, arg1 int, arg2 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIfVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIfVersion indicates an expected call of DeleteIfVersion.
+func (mr *MockCustomerRepositoryImplMockRecorder) DeleteIfVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfVersion", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).DeleteIfVersion), arg0, arg1, arg2)
 }
 
 // GetByID mocks base method.
-func (m *MockCustomerRepositoryImpl) GetByID(arg0 int) (*models.Customer, error) {
+func (m *MockCustomerRepositoryImpl) GetByID(arg0 context.Context, arg1 int) (*models.Customer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", arg0)
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
 	ret0, _ := ret[0].(*models.Customer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockCustomerRepositoryImplMockRecorder) GetByID(arg0 interface{}) *gomock.Call {
+func (mr *MockCustomerRepositoryImplMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).GetByID), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockCustomerRepositoryImpl) List(arg0 context.Context, arg1 repositories.Query) ([]models.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]models.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCustomerRepositoryImplMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).List), arg0, arg1)
+}
+
+// Patch mocks base method.
+func (m *MockCustomerRepositoryImpl) Patch(arg0 context.Context, arg1 *models.Customer, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockCustomerRepositoryImplMockRecorder) Patch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Patch), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockCustomerRepositoryImpl) Purge(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockCustomerRepositoryImplMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Purge), arg0, arg1)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockCustomerRepositoryImpl) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockCustomerRepositoryImplMockRecorder) PurgeDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).PurgeDeleted), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockCustomerRepositoryImpl) Restore(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockCustomerRepositoryImplMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).GetByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Restore), arg0, arg1)
 }
 
 // Update mocks base method.
-func (m *MockCustomerRepositoryImpl) Update(arg0 *models.Customer) error {
+func (m *MockCustomerRepositoryImpl) Update(arg0 context.Context, arg1 *models.Customer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockCustomerRepositoryImplMockRecorder) Update(arg0 interface{}) *gomock.Call {
+func (mr *MockCustomerRepositoryImplMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomerRepositoryImpl)(nil).Update), arg0, arg1)
 }
@@ -6,7 +6,10 @@ package mocks
 
 import (
 	models "backend/internal/models"
+	repositories "backend/internal/repositories"
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,89 +37,190 @@ func (m *MockOrderRepositoryImpl) EXPECT() *MockOrderRepositoryImplMockRecorder
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockOrderRepositoryImpl) Count(arg0 context.Context, arg1 repositories.Query) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockOrderRepositoryImplMockRecorder) Count(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Count), arg0, arg1)
+}
+
+// CountOrdersByUserID mocks base method.
+func (m *MockOrderRepositoryImpl) CountOrdersByUserID(arg0 context.Context, arg1 int, arg2 repositories.Query) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOrdersByUserID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOrdersByUserID indicates an expected call of CountOrdersByUserID.
+func (mr *MockOrderRepositoryImplMockRecorder) CountOrdersByUserID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrdersByUserID", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).CountOrdersByUserID), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
-func (m *MockOrderRepositoryImpl) Create(arg0 *models.Order) error {
+func (m *MockOrderRepositoryImpl) Create(arg0 context.Context, arg1 *models.Order) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockOrderRepositoryImplMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryImplMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Create), arg0, arg1)
 }
 
 // Delete mocks base method.
-func (m *MockOrderRepositoryImpl) Delete(arg0 int) error {
+func (m *MockOrderRepositoryImpl) Delete(arg0 context.Context, arg1 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockOrderRepositoryImplMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryImplMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Delete), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Delete), arg0, arg1)
 }
 
-// GetAll mocks base method.
-func (m *MockOrderRepositoryImpl) GetAll() ([]models.Order, error) {
+// DeleteIfVersion mocks base method.
+func (m *MockOrderRepositoryImpl) DeleteIfVersion(arg0 context.Context, arg1 int, arg2 uint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
-	ret0, _ := ret[0].([]models.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteIfVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockOrderRepositoryImplMockRecorder) GetAll() *gomock.Call {
+// DeleteIfVersion indicates an expected call of DeleteIfVersion.
+func (mr *MockOrderRepositoryImplMockRecorder) DeleteIfVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfVersion", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).DeleteIfVersion), arg0, arg1, arg2)
 }
 
 // GetByID mocks base method.
-func (m *MockOrderRepositoryImpl) GetByID(arg0 int) (*models.Order, error) {
+func (m *MockOrderRepositoryImpl) GetByID(arg0 context.Context, arg1 int) (*models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", arg0)
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
 	ret0, _ := ret[0].(*models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockOrderRepositoryImplMockRecorder) GetByID(arg0 interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryImplMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).GetByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).GetByID), arg0, arg1)
 }
 
 // GetOrdersByUserID mocks base method.
-func (m *MockOrderRepositoryImpl) GetOrdersByUserID(arg0 int) ([]models.Order, error) {
+func (m *MockOrderRepositoryImpl) GetOrdersByUserID(arg0 context.Context, arg1 int, arg2 repositories.Query) ([]models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrdersByUserID", arg0)
+	ret := m.ctrl.Call(m, "GetOrdersByUserID", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrdersByUserID indicates an expected call of GetOrdersByUserID.
-func (mr *MockOrderRepositoryImplMockRecorder) GetOrdersByUserID(arg0 interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryImplMockRecorder) GetOrdersByUserID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUserID", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).GetOrdersByUserID), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockOrderRepositoryImpl) List(arg0 context.Context, arg1 repositories.Query) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrderRepositoryImplMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).List), arg0, arg1)
+}
+
+// Patch mocks base method.
+func (m *MockOrderRepositoryImpl) Patch(arg0 context.Context, arg1 *models.Order, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockOrderRepositoryImplMockRecorder) Patch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Patch), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockOrderRepositoryImpl) Purge(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockOrderRepositoryImplMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Purge), arg0, arg1)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockOrderRepositoryImpl) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockOrderRepositoryImplMockRecorder) PurgeDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).PurgeDeleted), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockOrderRepositoryImpl) Restore(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockOrderRepositoryImplMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUserID", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).GetOrdersByUserID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Restore), arg0, arg1)
 }
 
 // Update mocks base method.
-func (m *MockOrderRepositoryImpl) Update(arg0 *models.Order) error {
+func (m *MockOrderRepositoryImpl) Update(arg0 context.Context, arg1 *models.Order) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockOrderRepositoryImplMockRecorder) Update(arg0 interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryImplMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrderRepositoryImpl)(nil).Update), arg0, arg1)
 }
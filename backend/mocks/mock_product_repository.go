@@ -0,0 +1,196 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/internal/repositories (interfaces: ProductRepositoryImpl)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "backend/internal/models"
+	repositories "backend/internal/repositories"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProductRepositoryImpl is a mock of ProductRepositoryImpl interface.
+type MockProductRepositoryImpl struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductRepositoryImplMockRecorder
+}
+
+// MockProductRepositoryImplMockRecorder is the mock recorder for MockProductRepositoryImpl.
+type MockProductRepositoryImplMockRecorder struct {
+	mock *MockProductRepositoryImpl
+}
+
+// NewMockProductRepositoryImpl creates a new mock instance.
+func NewMockProductRepositoryImpl(ctrl *gomock.Controller) *MockProductRepositoryImpl {
+	mock := &MockProductRepositoryImpl{ctrl: ctrl}
+	mock.recorder = &MockProductRepositoryImplMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductRepositoryImpl) EXPECT() *MockProductRepositoryImplMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockProductRepositoryImpl) Count(arg0 context.Context, arg1 repositories.Query) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockProductRepositoryImplMockRecorder) Count(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Count), arg0, arg1)
+}
+
+// Create mocks base method.
+func (m *MockProductRepositoryImpl) Create(arg0 context.Context, arg1 *models.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProductRepositoryImplMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockProductRepositoryImpl) Delete(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProductRepositoryImplMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Delete), arg0, arg1)
+}
+
+// DeleteIfVersion mocks base method.
+func (m *MockProductRepositoryImpl) DeleteIfVersion(arg0 context.Context, arg1 int, arg2 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIfVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIfVersion indicates an expected call of DeleteIfVersion.
+func (mr *MockProductRepositoryImplMockRecorder) DeleteIfVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfVersion", reflect.TypeOf((*MockProductRepositoryImpl)(nil).DeleteIfVersion), arg0, arg1, arg2)
+}
+
+// GetByID mocks base method.
+func (m *MockProductRepositoryImpl) GetByID(arg0 context.Context, arg1 int) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockProductRepositoryImplMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductRepositoryImpl)(nil).GetByID), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockProductRepositoryImpl) List(arg0 context.Context, arg1 repositories.Query) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProductRepositoryImplMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProductRepositoryImpl)(nil).List), arg0, arg1)
+}
+
+// Patch mocks base method.
+func (m *MockProductRepositoryImpl) Patch(arg0 context.Context, arg1 *models.Product, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockProductRepositoryImplMockRecorder) Patch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Patch), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockProductRepositoryImpl) Purge(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockProductRepositoryImplMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Purge), arg0, arg1)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockProductRepositoryImpl) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockProductRepositoryImplMockRecorder) PurgeDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockProductRepositoryImpl)(nil).PurgeDeleted), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockProductRepositoryImpl) Restore(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockProductRepositoryImplMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Restore), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockProductRepositoryImpl) Update(arg0 context.Context, arg1 *models.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProductRepositoryImplMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProductRepositoryImpl)(nil).Update), arg0, arg1)
+}
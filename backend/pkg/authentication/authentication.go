@@ -1,9 +1,14 @@
 package authentication
 
 import (
+	"context"
 	"errors"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/github"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"time"
 )
 
@@ -16,12 +21,72 @@ type Authentication struct {
 	UserID       string
 }
 
+// Principal is the user a request was authenticated as.
+type Principal struct {
+	UserID string
+	Login  string
+	Email  string
+	Admin  bool
+}
+
+type principalKey struct{}
+
+var tracer = otel.Tracer("backend/pkg/authentication")
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal stored in ctx by WithPrincipal, or nil
+// for unauthenticated contexts.
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
 // ValidateToken validates the access token using the Goth provider.
-func ValidateToken(accessToken string) (bool, error) {
+func ValidateToken(ctx context.Context, accessToken string) (bool, error) {
+	if _, err := Authenticate(ctx, accessToken); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Authenticate validates the access token using the Goth provider and returns
+// the user it belongs to. Admin is left for the caller to decide. Validated
+// tokens are cached for TokenCacheTTL.
+func Authenticate(ctx context.Context, accessToken string) (principal *Principal, err error) {
+	_, span := tracer.Start(ctx, "authentication.ValidateToken", trace.WithAttributes(attribute.String("auth.provider", "github")))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "token validation failed")
+		}
+		span.End()
+	}()
+
+	ttl := TokenCacheTTL
+	if ttl <= 0 {
+		return fetchPrincipal(accessToken)
+	}
+	if principal, ok := tokens.get(accessToken); ok {
+		span.SetAttributes(attribute.Bool("auth.cache_hit", true))
+		return principal, nil
+	}
+	span.SetAttributes(attribute.Bool("auth.cache_hit", false))
+	if principal, err = fetchPrincipal(accessToken); err != nil {
+		return nil, err
+	}
+	tokens.put(accessToken, principal, ttl)
+	return principal, nil
+}
+
+func fetchPrincipal(accessToken string) (*Principal, error) {
 	// Fetch the provider
 	provider, err := goth.GetProvider("github")
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// Create a session with the access token
@@ -33,14 +98,13 @@ func ValidateToken(accessToken string) (bool, error) {
 	// Use the provider to get the user and validate the token
 	user, err := provider.FetchUser(session)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// Check if the user is valid
 	if user.AccessToken == "" {
-		return false, errors.New("invalid access token")
+		return nil, errors.New("invalid access token")
 	}
 
-	// Additional validation logic can be added here if necessary
-	return true, nil
+	return &Principal{UserID: user.UserID, Login: user.NickName, Email: user.Email}, nil
 }
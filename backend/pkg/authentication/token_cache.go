@@ -0,0 +1,66 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// TokenCacheTTL is how long Authenticate trusts a token it has already
+// validated before asking the provider again. Zero disables the cache.
+var TokenCacheTTL time.Duration
+
+// OnTokenCacheLookup, when set, is told whether each cache lookup was a hit,
+// e.g. to count them in a metric.
+var OnTokenCacheLookup func(hit bool)
+
+// maxCachedTokens bounds the cache; expired entries are dropped once it is
+// full.
+const maxCachedTokens = 10000
+
+var tokens = &tokenCache{entries: make(map[[sha256.Size]byte]cachedPrincipal), now: time.Now}
+
+type cachedPrincipal struct {
+	principal Principal
+	expiresAt time.Time
+}
+
+// tokenCache maps hashed access tokens to the principal they belong to, so
+// the raw tokens are never kept in memory.
+type tokenCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedPrincipal
+	now     func() time.Time
+}
+
+func (c *tokenCache) get(accessToken string) (*Principal, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[sha256.Sum256([]byte(accessToken))]
+	hit := ok && c.now().Before(entry.expiresAt)
+	if OnTokenCacheLookup != nil {
+		OnTokenCacheLookup(hit)
+	}
+	if !hit {
+		return nil, false
+	}
+	principal := entry.principal
+	return &principal, true
+}
+
+func (c *tokenCache) put(accessToken string, principal *Principal, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxCachedTokens {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCachedTokens {
+			c.entries = make(map[[sha256.Size]byte]cachedPrincipal)
+		}
+	}
+	c.entries[sha256.Sum256([]byte(accessToken))] = cachedPrincipal{principal: *principal, expiresAt: now.Add(ttl)}
+}
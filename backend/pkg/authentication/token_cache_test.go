@@ -0,0 +1,38 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenCache(t *testing.T) {
+	now := time.Now()
+	cache := &tokenCache{entries: make(map[[sha256.Size]byte]cachedPrincipal), now: func() time.Time { return now }}
+	var lookups []bool
+	OnTokenCacheLookup = func(hit bool) { lookups = append(lookups, hit) }
+	t.Cleanup(func() { OnTokenCacheLookup = nil })
+
+	_, ok := cache.get("token")
+	assert.False(t, ok)
+
+	cache.put("token", &Principal{UserID: "1", Login: "octocat"}, time.Minute)
+	principal, ok := cache.get("token")
+	assert.True(t, ok)
+	assert.Equal(t, "octocat", principal.Login)
+
+	// Callers decide Admin on their copy; the cached entry must not change.
+	principal.Admin = true
+	principal, _ = cache.get("token")
+	assert.False(t, principal.Admin)
+
+	_, ok = cache.get("other")
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get("token")
+	assert.False(t, ok)
+	assert.Equal(t, []bool{false, true, true, false, false}, lookups)
+}
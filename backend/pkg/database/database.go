@@ -2,48 +2,111 @@ package database
 
 import (
 	"backend/internal/config"
+	"backend/internal/metrics"
 	"backend/internal/models"
+	"backend/internal/tracing"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
-	_ "gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"strings"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 var DB *gorm.DB
 
 // Connect initializes the database connection based on the environment
 func Connect(logger *logrus.Logger) error {
+	err := config.Load()
+	if err != nil {
+		return err
+	}
 
-	var (
-		err error
-		dsn string
-	)
+	if DB, err = Open(config.AppConfig, logger); err != nil {
+		return err
+	}
 
-	err = config.Load()
+	sqlDB, err := DB.DB()
 	if err != nil {
 		return err
 	}
+	if err := metrics.RegisterDBStats(config.AppConfig.DBName, sqlDB); err != nil {
+		logger.Warnf("failed to export database pool metrics: %v", err)
+	}
+
+	// Auto migrate the models
+	if err := Migrate(DB, logger); err != nil {
+		return err
+	}
+
+	return nil
+}
 
-	// Check the environment to determine the database driver and DSN
-	dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=Africa/Nairobi", config.AppConfig.DBHost, config.AppConfig.DBPort, config.AppConfig.DBUser, config.AppConfig.DBPassword, config.AppConfig.DBName)
-	// Connect to the database
-	if DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{}); err != nil {
+// Open opens a connection using the driver and DSN described by cfg without
+// touching the package level DB.
+func Open(cfg config.Config, logger *logrus.Logger) (*gorm.DB, error) {
+	dialector, err := Dialector(cfg)
+	if err != nil {
+		logger.Warnf("failed to configure database: %v", err)
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
 		logger.Warnf("failed to connect to database: %v", err)
-		return fmt.Errorf("failed to connect to database: %v", err)
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to instrument database: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to instrument database: %v", err)
+	}
+	return db, nil
+}
+
+// Dialector returns the gorm dialector for the configured driver. DATABASE_URL
+// takes precedence over the individual DB_* settings when it is set.
+func Dialector(cfg config.Config) (gorm.Dialector, error) {
+	switch cfg.DBDriver {
+	case DriverPostgres, "":
+		dsn := cfg.DatabaseURL
+		if dsn == "" {
+			dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=%s", cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode, cfg.DBTimeZone)
+		}
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		dsn := strings.TrimPrefix(cfg.DatabaseURL, "sqlite://")
+		dsn = strings.TrimPrefix(dsn, "sqlite:")
+		if dsn == "" {
+			dsn = cfg.DBName + ".db"
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.DBDriver)
 	}
+}
 
-	// Auto migrate the models
-	if err := DB.AutoMigrate(&models.Customer{}, &models.Product{}, &models.Order{}); err != nil {
+// Migrate creates or updates the tables backing the models, then applies
+// the versioned migrations
+func Migrate(db *gorm.DB, logger *logrus.Logger) error {
+	if err := db.AutoMigrate(&models.Customer{}, &models.Product{}, &models.Order{}, &models.IdempotencyKey{}, &models.AuditLog{}, &SchemaMigration{}); err != nil {
 		logger.Warnf("failed to auto migrate models: %v", err)
 		return fmt.Errorf("failed to auto migrate models: %v", err)
 	}
-
-	return nil
+	return applyMigrations(db, logger)
 }
 
 // Close closes the database connection
 func Close(logger *logrus.Logger) error {
+	if DB == nil {
+		return nil
+	}
 	db, err := DB.DB()
 	if err != nil {
 		logger.Warnf("failed to get database connection: %v", err)
@@ -56,9 +119,8 @@ func Close(logger *logrus.Logger) error {
 }
 
 // DropTables drops the database tables
-func DropTables(logger *logrus.Logger) error {
-	db := DB
-	err := db.Migrator().DropTable(&models.Order{}, &models.Product{}, &models.Customer{})
+func DropTables(db *gorm.DB, logger *logrus.Logger) error {
+	err := db.Migrator().DropTable(&models.Order{}, &models.Product{}, &models.Customer{}, &models.IdempotencyKey{}, &models.AuditLog{}, &SchemaMigration{})
 	if err != nil {
 		logger.Warnf("failed to drop tables: %v", err)
 		return fmt.Errorf("failed to drop tables: %v", err)
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SchemaMigration records a versioned migration that has been applied.
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// migration is a schema change AutoMigrate cannot express, such as composite
+// or partial indexes. Versions must only ever be appended.
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "order keyset pagination indexes",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				`CREATE INDEX IF NOT EXISTS idx_orders_created_at_id ON orders (created_at, id)`,
+				`CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at_id ON orders (user_id, created_at, id)`,
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "unique codes for live customers",
+		Up: func(tx *gorm.DB) error {
+			var duplicates []string
+			err := tx.Raw(`SELECT code FROM customers WHERE deleted_at IS NULL GROUP BY code HAVING COUNT(*) > 1`).Scan(&duplicates).Error
+			if err != nil {
+				return err
+			}
+			if len(duplicates) > 0 {
+				return fmt.Errorf("customer codes %v are shared by several customers; rename or delete the duplicates first", duplicates)
+			}
+			// Deleted customers keep their code so they can be restored, as long as
+			// no live customer has taken it in the meantime.
+			return execAll(tx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_code_live ON customers (code) WHERE deleted_at IS NULL`)
+		},
+	},
+}
+
+// applyMigrations runs every migration newer than the recorded schema
+// version, each in its own transaction.
+func applyMigrations(db *gorm.DB, logger *logrus.Logger) error {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			logger.Warnf("failed to apply migration %d (%s): %v", m.Version, m.Name, err)
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		logger.Infof("applied migration %d (%s)", m.Version, m.Name)
+	}
+	return nil
+}
+
+// SchemaVersion returns the highest applied migration version, or 0 when
+// none has been applied.
+func SchemaVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion is the version the database reaches once every
+// migration has been applied.
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func execAll(tx *gorm.DB, statements ...string) error {
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
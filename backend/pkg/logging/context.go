@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx whose request-scoped log fields include
+// fields, on top of any added earlier.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := make(logrus.Fields, len(fields))
+	if existing, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns logger scoped to the request ctx belongs to: it carries
+// the fields added with WithFields (request ID, principal) and, through the
+// trace hook, the current trace and span IDs.
+func FromContext(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	entry := logger.WithContext(ctx)
+	if fields, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		entry = entry.WithFields(fields)
+	}
+	return entry
+}
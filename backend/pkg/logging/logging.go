@@ -1,20 +1,51 @@
 package logging
 
 import (
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"os"
 )
 
+// Formats accepted by Configure.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
 var logger *logrus.Logger
 
+// init sets up JSON logging at info level, which holds until Configure
+// applies the configured level and format.
 func init() {
 	logger = logrus.New()
 	logger.SetOutput(os.Stdout)
 	logger.SetLevel(logrus.InfoLevel)
 	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(traceHook{})
 }
 
 // GetLogger returns the logger instance
 func GetLogger() *logrus.Logger {
 	return logger
 }
+
+// Configure sets the level (trace, debug, info, warn, error, fatal or panic)
+// and the format (json or text) of the logger.
+func Configure(level, format string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	var formatter logrus.Formatter
+	switch format {
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	case FormatText:
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	default:
+		return fmt.Errorf("unsupported log format: %s", format)
+	}
+	logger.SetLevel(lvl)
+	logger.SetFormatter(formatter)
+	return nil
+}
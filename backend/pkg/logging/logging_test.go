@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	defer func() { _ = Configure("info", FormatJSON) }()
+
+	assert.NoError(t, Configure("debug", FormatText))
+	assert.Equal(t, logrus.DebugLevel, GetLogger().GetLevel())
+	assert.IsType(t, &logrus.TextFormatter{}, GetLogger().Formatter)
+
+	assert.Error(t, Configure("loud", FormatJSON))
+	assert.Error(t, Configure("info", "xml"))
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := WithFields(context.Background(), logrus.Fields{"request_id": "req-1"})
+	ctx = WithFields(ctx, logrus.Fields{"principal": "octocat"})
+
+	entry := FromContext(ctx, GetLogger())
+	assert.Equal(t, logrus.Fields{"request_id": "req-1", "principal": "octocat"}, entry.Data)
+	assert.Equal(t, ctx, entry.Context)
+
+	assert.Empty(t, FromContext(context.Background(), GetLogger()).Data)
+}